@@ -0,0 +1,248 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultSignedNonceTTL = 60 // sec
+)
+
+var (
+	_ bind.ContractBackend = (*ContractBackend)(nil)
+	_ bind.DeployBackend   = (*ContractBackend)(nil)
+)
+
+// ContractBackend lets the abigen bindings in contract/ run on top of the client.
+// The transactions signed by the opts of Client.TransactOpts get the nonce from the nonce cache,
+// and are enqueued to the confirmer on send.
+type ContractBackend struct {
+	client *Client
+	ttl    int64 // sec, the signed txs never expire when 0
+
+	sync.Mutex
+	signed map[common.Hash]signedNonce // signed but not yet sent
+}
+
+type signedNonce struct {
+	from      common.Address
+	nonce     uint64
+	expiredAt int64
+}
+
+func (c *Client) Backend() *ContractBackend {
+	return c.backend
+}
+
+// TransactOpts returns the opts to transact with the bindings.
+// The fees are resolved from the fee caches, and the nonce is assigned when signing.
+// Note that the Nonce of the opts is ignored, and the signed tx should be sent through the backend
+// within the ttl of WithSignedNonceTTL, otherwise the assigned nonce is given back to be reused.
+func (c *Client) TransactOpts(ctx context.Context, priv string) (*bind.TransactOpts, error) {
+	privKey, err := crypto.HexToECDSA(priv)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse private key")
+	}
+
+	opts := &bind.TransactOpts{
+		From:    crypto.PubkeyToAddress(privKey.PublicKey),
+		Signer:  c.backend.signerFn(privKey),
+		Context: ctx,
+	}
+
+	isDynamic, err := c.isSupportEIP1559(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check eip1559 support")
+	}
+
+	if !isDynamic {
+		opts.GasPrice = new(big.Int).Set(c.GasPrice)
+		return opts, nil
+	}
+
+	if opts.GasTipCap, err = c.tipCash.GasTipCap(ctx, c); err != nil {
+		return nil, errors.Wrap(err, "failed to get GasTipCap")
+	}
+	if opts.GasFeeCap, err = c.baseFeeCash.GasFee(ctx, c, opts.GasTipCap); err != nil {
+		return nil, errors.Wrap(err, "failed to get FeeCap")
+	}
+
+	return opts, nil
+}
+
+func (b *ContractBackend) signerFn(privKey *ecdsa.PrivateKey) bind.SignerFn {
+	var (
+		from   = crypto.PubkeyToAddress(privKey.PublicKey)
+		signer = types.NewLondonSigner(b.client.chainID)
+	)
+
+	return func(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		if addr != from {
+			return nil, bind.ErrNotAuthorized
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeoutDuration)
+		defer cancel()
+
+		b.releaseExpired(ctx)
+
+		n, err := b.client.nonceCash.Nonce(ctx, from, b.client)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get nonce")
+		}
+
+		signedTx, err := types.SignNewTx(privKey, signer, withNonce(tx, b.client.chainID, n))
+		if err != nil {
+			_ = b.client.nonceCash.AddFailedNonce(ctx, from, n)
+			return nil, errors.Wrap(err, "at types.SignNewTx")
+		}
+
+		signed := signedNonce{from: from, nonce: n}
+		if b.ttl > 0 {
+			signed.expiredAt = time.Now().Unix() + b.ttl
+		}
+
+		b.Lock()
+		b.signed[signedTx.Hash()] = signed
+		b.Unlock()
+
+		return signedTx, nil
+	}
+}
+
+// releaseExpired gives back the nonces of the txs signed but not sent within the ttl
+func (b *ContractBackend) releaseExpired(ctx context.Context) {
+	var (
+		now     = time.Now().Unix()
+		expired []signedNonce
+	)
+
+	b.Lock()
+	for hash, signed := range b.signed {
+		if 0 < signed.expiredAt && signed.expiredAt <= now {
+			expired = append(expired, signed)
+			delete(b.signed, hash)
+		}
+	}
+	b.Unlock()
+
+	for _, signed := range expired {
+		if err := b.client.nonceCash.AddFailedNonce(ctx, signed.from, signed.nonce); err != nil {
+			b.client.logger.Warn().Err(err).Msgf("failed to give back nonce(=%d) of %s", signed.nonce, signed.from)
+		}
+	}
+}
+
+func withNonce(tx *types.Transaction, chainID *big.Int, nonce uint64) types.TxData {
+	if tx.Type() == types.DynamicFeeTxType {
+		return &types.DynamicFeeTx{
+			ChainID:    chainID,
+			Nonce:      nonce,
+			GasTipCap:  tx.GasTipCap(),
+			GasFeeCap:  tx.GasFeeCap(),
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}
+	}
+	return &types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: tx.GasPrice(),
+		Gas:      tx.Gas(),
+		To:       tx.To(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+	}
+}
+
+// SendTransaction enqueues the tx to the confirmer, and the failed nonce is given back to the nonce cache.
+// The tx sent after the expiry, or signed out of the backend, observes the nonce again like SendRawTx.
+func (b *ContractBackend) SendTransaction(ctx context.Context, tx *types.Transaction) (err error) {
+	b.Lock()
+	signed, ok := b.signed[tx.Hash()]
+	delete(b.signed, tx.Hash())
+	b.Unlock()
+
+	release := func(err error) {
+		if err != nil {
+			_ = b.client.nonceCash.AddFailedNonce(ctx, signed.from, signed.nonce)
+		}
+	}
+	if !ok {
+		from, err := types.Sender(types.LatestSignerForChainID(b.client.chainID), tx)
+		if err != nil {
+			return errors.Wrap(ErrInvalidSignature, err.Error())
+		}
+		if release, err = b.client.nonceCash.Observe(ctx, from, tx.Nonce(), b.client); err != nil {
+			return errors.Wrapf(err, "failed to observe nonce(=%d) of %s", tx.Nonce(), from)
+		}
+	}
+	defer func() { release(err) }()
+
+	if !b.client.pool.isHealthy() {
+		return ErrNoHealthyNode
+	}
+
+	if err = b.client.confirmer.EnqueueTx(ctx, tx); err != nil {
+		return errors.Wrapf(err, "failed to enqueue tx(%v)", tx.Hash())
+	}
+
+	return nil
+}
+
+func (b *ContractBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return b.client.pool.CodeAt(ctx, contract, blockNumber)
+}
+
+func (b *ContractBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return b.client.pool.CallContract(ctx, call, blockNumber)
+}
+
+func (b *ContractBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return b.client.pool.HeaderByNumber(ctx, number)
+}
+
+func (b *ContractBackend) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return b.client.pool.PendingCodeAt(ctx, account)
+}
+
+func (b *ContractBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return b.client.pool.PendingNonceAt(ctx, account)
+}
+
+func (b *ContractBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(b.client.GasPrice), nil
+}
+
+func (b *ContractBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return b.client.tipCash.GasTipCap(ctx, b.client)
+}
+
+func (b *ContractBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	return b.client.pool.EstimateGas(ctx, call)
+}
+
+func (b *ContractBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return b.client.pool.FilterLogs(ctx, query)
+}
+
+func (b *ContractBackend) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return b.client.pool.SubscribeFilterLogs(ctx, query, ch)
+}
+
+func (b *ContractBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return b.client.pool.TransactionReceipt(ctx, txHash)
+}
@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tak1827/eth-extended-client/contract"
+	"github.com/tak1827/transaction-confirmer/confirm"
+)
+
+func TestContractBackend(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		cfmOpts = []confirm.Opt{
+			confirm.WithWorkers(1),
+			confirm.WithWorkerInterval(64),
+			confirm.WithConfirmationBlock(0),
+		}
+		c, _    = NewClient(ctx, TestEndpoint, cfmOpts, WithTimeout(10), WithSyncSendConfirmInterval(64))
+		account = common.HexToAddress(TestAccount2)
+		amount  = ToWei(1.0, 9) // 1gwai
+	)
+
+	c.Start()
+	defer c.Stop()
+
+	opts, err := c.TransactOpts(ctx, TestPrivKey)
+	require.NoError(t, err)
+
+	addr, tx, erc20, err := contract.DeployERC20(opts, c.Backend(), "name", "symbol")
+	require.NoError(t, err)
+
+	_, err = bind.WaitDeployed(ctx, c.Backend(), tx)
+	require.NoError(t, err)
+
+	tx, err = erc20.Mint(opts, account, amount)
+	require.NoError(t, err)
+
+	_, err = bind.WaitMined(ctx, c.Backend(), tx)
+	require.NoError(t, err)
+
+	balance, err := erc20.BalanceOf(&bind.CallOpts{Context: ctx}, account)
+	require.NoError(t, err)
+	require.Equal(t, amount.String(), balance.String())
+
+	// the nonce cache follows the txs sent by bindings
+	_, err = c.SyncSend(ctx, TestPrivKey, &account, amount, nil, 0)
+	require.NoError(t, err)
+
+	code, err := c.Backend().CodeAt(ctx, addr, nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, code)
+}
+
+func TestContractBackendManagedNonce(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		f     = newFakeNode(1010, 10)
+		to, _ = GenerateAddr()
+		sent  = make(chan *types.Transaction, 2)
+	)
+	defer f.Close()
+
+	f.handle("eth_getBlockByNumber", func([]json.RawMessage) (interface{}, error) {
+		return &types.Header{Number: big.NewInt(10), Difficulty: big.NewInt(0), Time: uint64(time.Now().Unix()), BaseFee: big.NewInt(7)}, nil
+	})
+	f.handle("eth_maxPriorityFeePerGas", func([]json.RawMessage) (interface{}, error) {
+		return (*hexutil.Big)(big.NewInt(1)), nil
+	})
+	f.handle("eth_getTransactionCount", func([]json.RawMessage) (interface{}, error) {
+		return hexutil.Uint64(5), nil
+	})
+	f.handle("eth_getCode", func([]json.RawMessage) (interface{}, error) {
+		return hexutil.Bytes{0x01}, nil
+	})
+	f.handle("eth_estimateGas", func([]json.RawMessage) (interface{}, error) {
+		return hexutil.Uint64(50000), nil
+	})
+	f.handle(MethodSendRawTransaction, func(params []json.RawMessage) (interface{}, error) {
+		var raw hexutil.Bytes
+		_ = json.Unmarshal(params[0], &raw)
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return nil, err
+		}
+		sent <- tx
+		return tx.Hash(), nil
+	})
+
+	c, err := NewClient(ctx, f.URL, nil)
+	require.NoError(t, err)
+
+	c.Start()
+	defer c.Stop()
+
+	erc20, err := contract.NewERC20(to, c.Backend())
+	require.NoError(t, err)
+
+	opts, err := c.TransactOpts(ctx, TestPrivKey)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1), opts.GasTipCap)
+	require.Equal(t, big.NewInt(15), opts.GasFeeCap)
+
+	for _, nonce := range []uint64{5, 6} {
+		tx, err := erc20.Transfer(opts, to, big.NewInt(1))
+		require.NoError(t, err)
+		require.Equal(t, nonce, tx.Nonce())
+		require.Equal(t, tx.Hash(), (<-sent).Hash())
+		require.True(t, c.unconfirmedTx.has(tx.Hash().Hex()))
+	}
+
+	var (
+		b      = c.Backend()
+		expire = func(tx *types.Transaction) {
+			b.Lock()
+			signed := b.signed[tx.Hash()]
+			signed.expiredAt = time.Now().Unix() - 1
+			b.signed[tx.Hash()] = signed
+			b.Unlock()
+		}
+	)
+
+	// the nonce of the tx signed but not sent is given back after the ttl
+	opts.NoSend = true
+	unsent, err := erc20.Transfer(opts, to, big.NewInt(1))
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), unsent.Nonce())
+	expire(unsent)
+
+	opts.NoSend = false
+	tx, err := erc20.Transfer(opts, to, big.NewInt(2))
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), tx.Nonce())
+	require.Equal(t, tx.Hash(), (<-sent).Hash())
+
+	b.Lock()
+	require.Empty(t, b.signed)
+	b.Unlock()
+
+	// the expired tx sent later takes back the given back nonce
+	opts.NoSend = true
+	late, err := erc20.Transfer(opts, to, big.NewInt(3))
+	require.NoError(t, err)
+	require.Equal(t, uint64(8), late.Nonce())
+	expire(late)
+	b.releaseExpired(ctx)
+
+	require.NoError(t, b.SendTransaction(ctx, late))
+	require.Equal(t, late.Hash(), (<-sent).Hash())
+
+	opts.NoSend = false
+	tx, err = erc20.Transfer(opts, to, big.NewInt(4))
+	require.NoError(t, err)
+	require.Equal(t, uint64(9), tx.Nonce())
+	require.Equal(t, tx.Hash(), (<-sent).Hash())
+
+	// the signed tx never expires without the ttl
+	b.ttl = 0
+	opts.NoSend = true
+	kept, err := erc20.Transfer(opts, to, big.NewInt(5))
+	require.NoError(t, err)
+	b.releaseExpired(ctx)
+
+	b.Lock()
+	require.Contains(t, b.signed, kept.Hash())
+	b.Unlock()
+}
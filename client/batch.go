@@ -0,0 +1,222 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultBatchSize            = 100
+	DefaultConfirmBatchTTL      = int64(500) // 500ms
+	MethodGetTransactionReceipt = "eth_getTransactionReceipt"
+
+	// the heads are regarded as stopped when no head arrives for this duration
+	maxHeadSilence = 30 * time.Second
+)
+
+// Batch collects read calls to send them in a single json-rpc batch request.
+// The results are written to the given destinations after Client.Batch returns.
+type Batch struct {
+	elems   []rpc.BatchElem
+	decodes []func()
+}
+
+func NewBatch() *Batch {
+	return &Batch{}
+}
+
+func (b *Batch) Len() int {
+	return len(b.elems)
+}
+
+func (b *Batch) add(method string, result interface{}, decode func(), args ...interface{}) *Batch {
+	b.elems = append(b.elems, rpc.BatchElem{Method: method, Args: args, Result: result})
+	b.decodes = append(b.decodes, decode)
+	return b
+}
+
+func (b *Batch) BalanceOf(account common.Address, balance *big.Int) *Batch {
+	result := new(hexutil.Big)
+	return b.add("eth_getBalance", result, func() {
+		balance.Set((*big.Int)(result))
+	}, account, "latest")
+}
+
+func (b *Batch) NonceAt(account common.Address, nonce *uint64) *Batch {
+	result := new(hexutil.Uint64)
+	return b.add("eth_getTransactionCount", result, func() {
+		*nonce = uint64(*result)
+	}, account, "latest")
+}
+
+func (b *Batch) PendingNonceAt(account common.Address, nonce *uint64) *Batch {
+	result := new(hexutil.Uint64)
+	return b.add("eth_getTransactionCount", result, func() {
+		*nonce = uint64(*result)
+	}, account, "pending")
+}
+
+func (b *Batch) Call(to common.Address, input []byte, output *[]byte) *Batch {
+	result := new(hexutil.Bytes)
+	return b.add("eth_call", result, func() {
+		*output = *result
+	}, toCallArg(ethereum.CallMsg{To: &to, Data: input}), "latest")
+}
+
+func (b *Batch) Receipt(hash common.Hash, receipt **types.Receipt) *Batch {
+	return b.add(MethodGetTransactionReceipt, receipt, nil, hash)
+}
+
+func (b *Batch) BlockNumber(number *uint64) *Batch {
+	result := new(hexutil.Uint64)
+	return b.add("eth_blockNumber", result, func() {
+		*number = uint64(*result)
+	})
+}
+
+// Batch sends all calls of b in batch requests, which are split by the batch size.
+// The returned errs are the errors of each call in the order added,
+// and err is returned only when the requests fail.
+func (c *Client) Batch(ctx context.Context, b *Batch) (errs []error, err error) {
+	for start := 0; start < len(b.elems); start += c.batchSize {
+		end := start + c.batchSize
+		if end > len(b.elems) {
+			end = len(b.elems)
+		}
+		if err = c.pool.BatchCallContext(ctx, b.elems[start:end]); err != nil {
+			err = errors.Wrap(err, "failed to batch call")
+			return
+		}
+	}
+
+	errs = make([]error, len(b.elems))
+	for i := range b.elems {
+		if errs[i] = b.elems[i].Error; errs[i] == nil && b.decodes[i] != nil {
+			b.decodes[i]()
+		}
+	}
+	return
+}
+
+// receiptBatch fetches the receipts of all unconfirmed txs and the head at once,
+// so that the confirmation of N txs costs one batch request instead of N*2 requests.
+type receiptBatch struct {
+	sync.Mutex
+	receipts  map[string]*types.Receipt
+	errs      map[string]error
+	head      uint64
+	fetchedAt time.Time
+	ttl       time.Duration
+
+	// while the heads are arriving, the receipts are reused until the next head,
+	// otherwise they are reused for the ttl
+	headsLive   bool
+	lastHeadAt  time.Time
+	invalidated bool
+}
+
+func (r *receiptBatch) get(ctx context.Context, c *Client, hash string) (*types.Receipt, uint64, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	_, ok := r.receipts[hash]
+	if !ok || r.invalidated || (!r.headDriven() && time.Since(r.fetchedAt) >= r.ttl) {
+		if err := r.fetch(ctx, c, hash); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return r.receipts[hash], r.head, r.errs[hash]
+}
+
+func (r *receiptBatch) headDriven() bool {
+	return r.headsLive && time.Since(r.lastHeadAt) < maxHeadSilence
+}
+
+// onHead forces the next get to fetch, and keeps the receipts until the next head
+func (r *receiptBatch) onHead() {
+	r.Lock()
+	r.invalidated = true
+	r.headsLive = true
+	r.lastHeadAt = time.Now()
+	r.Unlock()
+}
+
+// headsDown falls back to the ttl until the next head arrives
+func (r *receiptBatch) headsDown() {
+	r.Lock()
+	r.headsLive = false
+	r.Unlock()
+}
+
+func (r *receiptBatch) fetch(ctx context.Context, c *Client, hash string) error {
+	var (
+		hashes   = append(c.unconfirmedTx.keys(), hash)
+		receipts = make([]*types.Receipt, len(hashes))
+		head     uint64
+		b        = NewBatch().BlockNumber(&head)
+	)
+
+	for i := range hashes {
+		b.Receipt(common.HexToHash(hashes[i]), &receipts[i])
+	}
+
+	errs, err := c.Batch(ctx, b)
+	if err != nil {
+		return err
+	}
+	if errs[0] != nil {
+		return errors.Wrap(errs[0], "err LatestBlockNumber")
+	}
+
+	r.receipts = make(map[string]*types.Receipt, len(hashes))
+	r.errs = make(map[string]error)
+	for i := range hashes {
+		r.receipts[hashes[i]] = receipts[i]
+		if errs[i+1] != nil {
+			r.errs[hashes[i]] = errors.Wrap(errs[i+1], "err TransactionReceipt")
+		} else if receipts[i] == nil {
+			r.errs[hashes[i]] = ethereum.NotFound
+		}
+	}
+	r.head = head
+	r.fetchedAt = time.Now()
+	r.invalidated = false
+
+	return nil
+}
+
+func toCallArg(msg ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["data"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	if msg.GasTipCap != nil {
+		arg["maxPriorityFeePerGas"] = (*hexutil.Big)(msg.GasTipCap)
+	}
+	if msg.GasFeeCap != nil {
+		arg["maxFeePerGas"] = (*hexutil.Big)(msg.GasFeeCap)
+	}
+	return arg
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tak1827/transaction-confirmer/confirm"
+)
+
+func TestBatch(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		f       = newFakeNode(1010, 10)
+		account = common.HexToAddress(TestAccount)
+	)
+	defer f.Close()
+
+	f.handle("eth_getBalance", func([]json.RawMessage) (interface{}, error) {
+		return (*hexutil.Big)(big.NewInt(100)), nil
+	})
+	f.handle("eth_getTransactionCount", func([]json.RawMessage) (interface{}, error) {
+		return hexutil.Uint64(7), nil
+	})
+	f.handle("eth_call", func([]json.RawMessage) (interface{}, error) {
+		return hexutil.Bytes{0x01}, nil
+	})
+
+	c, err := NewClient(ctx, f.URL, nil, WithBatchSize(2))
+	require.NoError(t, err)
+
+	var (
+		balance = new(big.Int)
+		nonce   uint64
+		output  []byte
+		code    []byte
+		b       = NewBatch().BalanceOf(account, balance).NonceAt(account, &nonce).Call(account, nil, &output)
+	)
+	b.add("eth_getCode", new(hexutil.Bytes), func() { code = []byte{0x01} }, account, "latest")
+
+	requests := f.requests
+	errs, err := c.Batch(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, 2, f.requests-requests)
+
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	require.NoError(t, errs[2])
+	require.Error(t, errs[3])
+
+	require.Equal(t, big.NewInt(100), balance)
+	require.Equal(t, uint64(7), nonce)
+	require.Equal(t, []byte{0x01}, output)
+	require.Nil(t, code)
+}
+
+func TestConfirmTxBatch(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		f       = newFakeNode(1010, 10)
+		mined   = common.HexToHash("0x01")
+		pending = common.HexToHash("0x02")
+	)
+	defer f.Close()
+
+	f.handle(MethodGetTransactionReceipt, func(params []json.RawMessage) (interface{}, error) {
+		var hash common.Hash
+		_ = json.Unmarshal(params[0], &hash)
+		if hash != mined {
+			return nil, nil
+		}
+		return &types.Receipt{
+			Status:      types.ReceiptStatusSuccessful,
+			TxHash:      hash,
+			BlockNumber: big.NewInt(8),
+			Logs:        []*types.Log{},
+		}, nil
+	})
+
+	c, err := NewClient(ctx, f.URL, nil)
+	require.NoError(t, err)
+
+	c.unconfirmedTx.add(mined.Hex())
+	c.unconfirmedTx.add(pending.Hex())
+
+	requests := f.requests
+	require.NoError(t, c.ConfirmTx(ctx, mined.Hex(), 2))
+	require.ErrorIs(t, c.ConfirmTx(ctx, mined.Hex(), 3), confirm.ErrTxConfirmPending)
+	require.ErrorIs(t, c.ConfirmTx(ctx, pending.Hex(), 0), confirm.ErrTxNotFound)
+	require.Equal(t, 1, f.requests-requests)
+	require.Equal(t, 1, f.called("eth_blockNumber"))
+}
+
+func TestConfirmTxBatchHeadDriven(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		f     = newFakeNode(1010, 10)
+		mined = common.HexToHash("0x01")
+	)
+	defer f.Close()
+
+	f.handle(MethodGetTransactionReceipt, func(params []json.RawMessage) (interface{}, error) {
+		return &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: mined, BlockNumber: big.NewInt(8), Logs: []*types.Log{}}, nil
+	})
+
+	c, err := NewClient(ctx, f.URL, nil, WithConfirmBatchTTL(10))
+	require.NoError(t, err)
+	c.unconfirmedTx.add(mined.Hex())
+
+	// the receipts are kept until the next head beyond the ttl
+	c.receipts.onHead()
+	require.NoError(t, c.ConfirmTx(ctx, mined.Hex(), 0))
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, c.ConfirmTx(ctx, mined.Hex(), 0))
+	require.Equal(t, 1, f.called("eth_blockNumber"))
+
+	c.receipts.onHead()
+	require.NoError(t, c.ConfirmTx(ctx, mined.Hex(), 0))
+	require.Equal(t, 2, f.called("eth_blockNumber"))
+
+	// the ttl applies again once the heads stop
+	c.receipts.headsDown()
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, c.ConfirmTx(ctx, mined.Hex(), 0))
+	require.Equal(t, 3, f.called("eth_blockNumber"))
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+var (
+	BlockLatest    = BlockRef{tag: "latest"}
+	BlockPending   = BlockRef{tag: "pending"}
+	BlockSafe      = BlockRef{tag: "safe"}
+	BlockFinalized = BlockRef{tag: "finalized"}
+	BlockEarliest  = BlockRef{tag: "earliest"}
+)
+
+// BlockRef specifies the block to read the state at, by the number, the hash or the tag.
+// The zero value is the latest block.
+type BlockRef struct {
+	number *big.Int
+	hash   *common.Hash
+	tag    string
+}
+
+func BlockAt(number uint64) BlockRef {
+	return BlockRef{number: new(big.Int).SetUint64(number)}
+}
+
+// BlockAtHash refers the block by the hash (EIP-1898), which fails when the block is not canonical
+func BlockAtHash(hash common.Hash) BlockRef {
+	return BlockRef{hash: &hash}
+}
+
+func (r BlockRef) String() string {
+	switch {
+	case r.hash != nil:
+		return r.hash.Hex()
+	case r.number != nil:
+		return r.number.String()
+	case r.tag != "":
+		return r.tag
+	}
+	return "latest"
+}
+
+func (r BlockRef) MarshalJSON() ([]byte, error) {
+	switch {
+	case r.hash != nil:
+		return json.Marshal(map[string]interface{}{
+			"blockHash":        r.hash,
+			"requireCanonical": true,
+		})
+	case r.number != nil:
+		return json.Marshal((*hexutil.Big)(r.number))
+	case r.tag != "":
+		return json.Marshal(r.tag)
+	}
+	return json.Marshal("latest")
+}
+
+// OverrideAccount is the hypothetical state of the account in eth_call. The nil fields are not overridden.
+// State replaces the whole storage, while StateDiff replaces only the given slots.
+type OverrideAccount struct {
+	Nonce     *uint64
+	Code      []byte
+	Balance   *big.Int
+	State     map[common.Hash]common.Hash
+	StateDiff map[common.Hash]common.Hash
+}
+
+type StateOverride map[common.Address]OverrideAccount
+
+func (o OverrideAccount) MarshalJSON() ([]byte, error) {
+	type override struct {
+		Nonce     *hexutil.Uint64             `json:"nonce,omitempty"`
+		Code      *hexutil.Bytes              `json:"code,omitempty"`
+		Balance   *hexutil.Big                `json:"balance,omitempty"`
+		State     map[common.Hash]common.Hash `json:"state,omitempty"`
+		StateDiff map[common.Hash]common.Hash `json:"stateDiff,omitempty"`
+	}
+
+	v := override{
+		Nonce:     (*hexutil.Uint64)(o.Nonce),
+		Balance:   (*hexutil.Big)(o.Balance),
+		State:     o.State,
+		StateDiff: o.StateDiff,
+	}
+	if o.Code != nil {
+		v.Code = (*hexutil.Bytes)(&o.Code)
+	}
+	return json.Marshal(v)
+}
+
+// QueryContractAt is QueryContract at the block
+func (c *Client) QueryContractAt(ctx context.Context, to common.Address, input []byte, block BlockRef) ([]byte, error) {
+	return c.SimulateCall(ctx, ethereum.CallMsg{To: &to, Data: input}, block, nil)
+}
+
+// SimulateCall runs eth_call of the msg at the block against the state overridden by the overrides.
+// Like QueryContract, the empty output from the account without code is an error.
+func (c *Client) SimulateCall(ctx context.Context, msg ethereum.CallMsg, block BlockRef, overrides StateOverride) ([]byte, error) {
+	var (
+		output hexutil.Bytes
+		args   = []interface{}{toCallArg(msg), block}
+	)
+	if len(overrides) > 0 {
+		args = append(args, overrides)
+	}
+
+	if err := c.pool.CallContext(ctx, &output, "eth_call", args...); err != nil {
+		return nil, errors.Wrapf(err, "failed to call contract(=%s) at %s", msg.To, block)
+	}
+
+	if len(output) == 0 && msg.To != nil {
+		if override, ok := overrides[*msg.To]; ok && override.Code != nil {
+			return output, nil
+		}
+
+		var code hexutil.Bytes
+		if err := c.pool.CallContext(ctx, &code, "eth_getCode", msg.To, block); err != nil {
+			return nil, errors.Wrap(err, "at ethclient.CodeAt")
+		} else if len(code) == 0 {
+			return nil, errors.Wrap(bind.ErrNoCode, "at ethclient.CodeAt")
+		}
+	}
+
+	return output, nil
+}
+
+func (c *Client) BalanceAt(ctx context.Context, account common.Address, block BlockRef) (*big.Int, error) {
+	var balance hexutil.Big
+	if err := c.pool.CallContext(ctx, &balance, "eth_getBalance", account, block); err != nil {
+		return nil, errors.Wrapf(err, "failed to get balance at %s", block)
+	}
+	return (*big.Int)(&balance), nil
+}
+
+func (c *Client) NonceAt(ctx context.Context, account common.Address, block BlockRef) (uint64, error) {
+	var nonce hexutil.Uint64
+	if err := c.pool.CallContext(ctx, &nonce, "eth_getTransactionCount", account, block); err != nil {
+		return 0, errors.Wrapf(err, "failed to get nonce at %s", block)
+	}
+	return uint64(nonce), nil
+}
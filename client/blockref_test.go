@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockRef(t *testing.T) {
+	hash := common.HexToHash("0x01")
+
+	for _, tc := range []struct {
+		ref  BlockRef
+		want string
+	}{
+		{BlockRef{}, `"latest"`},
+		{BlockSafe, `"safe"`},
+		{BlockFinalized, `"finalized"`},
+		{BlockPending, `"pending"`},
+		{BlockAt(16), `"0x10"`},
+		{BlockAtHash(hash), `{"blockHash":"` + hash.Hex() + `","requireCanonical":true}`},
+	} {
+		b, err := json.Marshal(tc.ref)
+		require.NoError(t, err)
+		require.Equal(t, tc.want, string(b))
+	}
+}
+
+func TestSimulateCall(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		f      = newFakeNode(1010, 10)
+		target = common.HexToAddress(TestAccount)
+		nonce  = uint64(1)
+		params []json.RawMessage
+	)
+	defer f.Close()
+
+	f.handle("eth_call", func(p []json.RawMessage) (interface{}, error) {
+		params = p
+		return hexutil.Bytes{}, nil
+	})
+	f.handle("eth_getCode", func([]json.RawMessage) (interface{}, error) {
+		return hexutil.Bytes{}, nil
+	})
+
+	c, err := NewClient(ctx, f.URL, nil)
+	require.NoError(t, err)
+
+	overrides := StateOverride{target: OverrideAccount{
+		Nonce:     &nonce,
+		Code:      []byte{0x60},
+		Balance:   big.NewInt(16),
+		StateDiff: map[common.Hash]common.Hash{common.HexToHash("0x00"): common.HexToHash("0x01")},
+	}}
+	_, err = c.SimulateCall(ctx, ethereum.CallMsg{To: &target}, BlockAt(8), overrides)
+	require.NoError(t, err)
+
+	require.Len(t, params, 3)
+	require.JSONEq(t, `"0x8"`, string(params[1]))
+	require.JSONEq(t, `{"`+hexutil.Encode(target.Bytes())+`":{
+		"nonce":"0x1",
+		"code":"0x60",
+		"balance":"0x10",
+		"stateDiff":{"0x0000000000000000000000000000000000000000000000000000000000000000":"0x0000000000000000000000000000000000000000000000000000000000000001"}
+	}}`, string(params[2]))
+	require.Equal(t, 0, f.called("eth_getCode"))
+
+	// without the overridden code, the account is checked to have code
+	_, err = c.QueryContractAt(ctx, target, nil, BlockFinalized)
+	require.ErrorIs(t, err, bind.ErrNoCode)
+	require.Len(t, params, 2)
+	require.JSONEq(t, `"finalized"`, string(params[1]))
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 	"github.com/tak1827/go-cache/lru"
 	"github.com/tak1827/nonce-incrementor/nonce"
@@ -38,7 +39,7 @@ func (c *TipCapCash) GasTipCap(ctx context.Context, client *Client) (*big.Int, e
 		return gas, nil
 	}
 
-	tip, err := client.ethclient.SuggestGasTipCap(ctx)
+	tip, err := client.pool.SuggestGasTipCap(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get suggestion")
 	}
@@ -69,7 +70,7 @@ func (c *BaseFeeCash) GasFee(ctx context.Context, client *Client, tip *big.Int)
 	c.Unlock()
 
 	if expired {
-		head, err := client.ethclient.HeaderByNumber(ctx, nil)
+		head, err := client.pool.HeaderByNumber(ctx, nil)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to get block header")
 		}
@@ -85,51 +86,223 @@ func (c *BaseFeeCash) GasFee(ctx context.Context, client *Client, tip *big.Int)
 	return new(big.Int).Add(tip, new(big.Int).Mul(baseFee, big.NewInt(2))), nil
 }
 
+func (c *BaseFeeCash) update(base *big.Int) {
+	c.Lock()
+	c.base = base
+	c.expiredAt = time.Now().Unix() + c.ttl
+	c.Unlock()
+}
+
+// NonceCash is keyed by the address, so the txs signed by the client and the ones signed out of the client,
+// such as by BuildTx or SendRawTx, share the same nonce of the account
 type NonceCash struct {
 	sync.Mutex
 	nonces lru.LRUCache
 }
 
-func (c *NonceCash) Nonce(ctx context.Context, priv string, client *Client) (uint64, error) {
+// accountNonce is the nonce of the account with the bookkeeping of the nonces out of the client
+type accountNonce struct {
+	*nonce.Nonce
+	issued map[uint64]struct{} // assigned by BuildTx, but not yet sent
+	failed map[uint64]struct{} // the failed list of the Nonce, to reclaim the given back nonce
+}
+
+func (a *accountNonce) assign() (uint64, error) {
+	n, err := a.Assign()
+	if err != nil {
+		return 0, err
+	}
+	delete(a.failed, n)
+	return n, nil
+}
+
+func (a *accountNonce) giveBack(n uint64) error {
+	if err := a.AddFailedNonce(n); err != nil {
+		return err
+	}
+	a.failed[n] = struct{}{}
+	return nil
+}
+
+// reclaim takes the given back nonce out of the failed list, so that it is not assigned again
+func (a *accountNonce) reclaim(n uint64) bool {
+	if _, ok := a.failed[n]; !ok {
+		return false
+	}
+
+	// the failed list is popped from the smallest by Assign
+	others := make([]uint64, 0, len(a.failed))
+	for range a.failed {
+		m, err := a.Assign()
+		if err != nil {
+			break
+		}
+		if m != n {
+			others = append(others, m)
+		}
+	}
+
+	a.failed = make(map[uint64]struct{})
+	for _, m := range others {
+		_ = a.giveBack(m)
+	}
+	return true
+}
+
+func (c *NonceCash) Nonce(ctx context.Context, addr common.Address, client *Client) (uint64, error) {
 	c.Lock()
 	defer c.Unlock()
 
-	v, ok := c.nonces.Get(priv)
-	if ok {
-		return v.(*nonce.Nonce).Assign()
+	a, err := c.get(ctx, addr, client)
+	if err != nil {
+		return 0, err
+	}
+
+	return a.assign()
+}
+
+// get returns the nonce of the addr, which should be called with the lock held
+func (c *NonceCash) get(ctx context.Context, addr common.Address, client *Client) (*accountNonce, error) {
+	key := addr.Hex()
+
+	if v, ok := c.nonces.Get(key); ok {
+		return v.(*accountNonce), nil
 	}
 
 	ensure := true
-	n, err := nonce.NewNonce(ctx, client, priv, ensure, 0)
+	n, err := nonce.NewNonce(ctx, addressNoncer{client}, key, ensure, 0)
 	if err != nil {
-		return 0, errors.Wrap(err, "failed to new nonce")
+		return nil, errors.Wrap(err, "failed to new nonce")
 	}
 
-	c.nonces.Add(priv, n)
+	a := &accountNonce{Nonce: n, issued: make(map[uint64]struct{}), failed: make(map[uint64]struct{})}
+	c.nonces.Add(key, a)
 
-	return n.Assign()
+	return a, nil
+}
+
+// Issue assigns the nonce to the tx built out of the client, which is given back when the send of it failed
+func (c *NonceCash) Issue(ctx context.Context, addr common.Address, client *Client) (uint64, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	a, err := c.get(ctx, addr, client)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := a.assign()
+	if err != nil {
+		return 0, err
+	}
+	a.issued[n] = struct{}{}
+
+	return n, nil
+}
+
+// release gives back the nonce issued by Issue, when the tx is not built
+func (c *NonceCash) release(addr common.Address, n uint64) {
+	c.Lock()
+	defer c.Unlock()
+
+	if v, ok := c.nonces.Get(addr.Hex()); ok {
+		a := v.(*accountNonce)
+		delete(a.issued, n)
+		_ = a.giveBack(n)
+	}
+}
+
+// Observe registers the nonce used by the tx signed out of the client before it is sent,
+// so that the nonce of the account is assigned after it. The nonce ahead of the next one fails with ErrNonceGap.
+// The release is called with the result of the send, which gives back the nonce issued or observed here on failure,
+// unless the nonce is already used, such as by "nonce too low" or "already known".
+func (c *NonceCash) Observe(ctx context.Context, addr common.Address, n uint64, client *Client) (release func(error), err error) {
+	c.Lock()
+	defer c.Unlock()
+
+	a, err := c.get(ctx, addr, client)
+	if err != nil {
+		return nil, err
+	}
+
+	giveBack := func(err error) {
+		c.Lock()
+		defer c.Unlock()
+
+		delete(a.issued, n)
+		if err != nil && !isNonceUsed(err) {
+			_ = a.giveBack(n)
+		}
+	}
+
+	if _, ok := a.issued[n]; ok {
+		return giveBack, nil
+	}
+	if a.reclaim(n) {
+		// given back before, such as by the expiry of the signed tx
+		return giveBack, nil
+	}
+
+	prior, err := a.Current()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get current nonce")
+	}
+	if prior > n {
+		// the nonce is assigned by the others, such as the rebroadcast
+		return func(error) {}, nil
+	}
+	if prior < n {
+		pending, err := client.pool.PendingNonceAt(ctx, addr)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get pending nonce")
+		}
+		if pending < n {
+			return nil, errors.Wrapf(ErrNonceGap, "nonce=%d, next=%d", n, prior)
+		}
+		if pending > n {
+			// sent out of the client
+			return func(error) {}, nil
+		}
+	}
+	a.Reset(n + 1)
+
+	return func(err error) {
+		if err == nil || isNonceUsed(err) {
+			return
+		}
+
+		c.Lock()
+		defer c.Unlock()
+
+		// rewind unless the following nonces are assigned in the meantime
+		if current, err := a.Current(); err == nil && current == n+1 {
+			a.Reset(prior)
+			return
+		}
+		_ = a.giveBack(n)
+	}, nil
 }
 
-func (c *NonceCash) Current(ctx context.Context, priv string) (uint64, error) {
+func (c *NonceCash) Current(ctx context.Context, addr common.Address) (uint64, error) {
 	c.Lock()
 	defer c.Unlock()
 
-	v, ok := c.nonces.Get(priv)
+	v, ok := c.nonces.Get(addr.Hex())
 	if !ok {
 		panic("ops")
 	}
 
-	return v.(*nonce.Nonce).Next()
+	return v.(*accountNonce).Next()
 }
 
-func (c *NonceCash) AddFailedNonce(ctx context.Context, priv string, n uint64) error {
+func (c *NonceCash) AddFailedNonce(ctx context.Context, addr common.Address, n uint64) error {
 	c.Lock()
 	defer c.Unlock()
 
-	v, ok := c.nonces.Get(priv)
+	v, ok := c.nonces.Get(addr.Hex())
 	if !ok {
-		return fmt.Errorf("no nonce for %s", priv)
+		return fmt.Errorf("no nonce for %s", addr)
 	}
 
-	return v.(*nonce.Nonce).AddFailedNonce(n)
+	return v.(*accountNonce).giveBack(n)
 }
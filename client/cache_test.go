@@ -5,6 +5,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 )
 
@@ -45,9 +46,9 @@ func TestNonceCash(t *testing.T) {
 		c, _ = NewClient(ctx, TestEndpoint, nil, WithTimeout(10))
 	)
 
-	n, err := c.nonceCash.Nonce(ctx, TestPrivKey3, &c)
+	n, err := c.nonceCash.Nonce(ctx, common.HexToAddress(TestAccount3), &c)
 	require.NoError(t, err)
 	require.Equal(t, uint64(0), n)
 
-	require.True(t, c.nonceCash.nonces.Contains(TestPrivKey3))
+	require.True(t, c.nonceCash.nonces.Contains(common.HexToAddress(TestAccount3).Hex()))
 }
@@ -12,8 +12,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"github.com/tak1827/go-cache/lru"
@@ -29,7 +27,30 @@ var (
 )
 
 type Client struct {
-	ethclient *ethclient.Client
+	pool                *nodePool
+	endpoints           []string
+	healthCheckInterval int64
+	maxHeadLag          uint64
+	maxHeadAge          int64
+	minPeerCount        int64
+	retryPolicy         RetryPolicy
+	retryPolicies       map[string]RetryPolicy
+	readRateLimit       float64
+	readBurst           int
+	writeRateLimit      float64
+	writeBurst          int
+	batchSize           int
+	confirmBatchTTL     int64
+	receipts            *receiptBatch
+	heads               *headWatcher
+	headPollInterval    int64
+	backend             *ContractBackend
+	multicall           *multicaller
+	multicallAddress    common.Address
+	multicallChunkSize  int
+	preflight           bool
+	create2Deployer     common.Address
+	logRangeLimit       uint64
 
 	GasPrice *big.Int
 	chainID  *big.Int
@@ -38,6 +59,7 @@ type Client struct {
 	tipCapCashTTL  int64
 	tipCash        *TipCapCash
 	baseFeeCashTTL int64
+	signedNonceTTL int64
 	baseFeeCash    *BaseFeeCash
 	nonceCash      *NonceCash
 
@@ -53,34 +75,61 @@ type Client struct {
 }
 
 func NewClient(ctx context.Context, endpoint string, cfmOpts []confirm.Opt, opts ...Option) (c Client, err error) {
-	rpcclient, err := rpc.DialContext(ctx, endpoint)
-	if err != nil {
-		err = errors.Wrapf(err, "failed to conecting endpoint(%s)", endpoint)
-		return
-	}
-
-	c.ethclient = ethclient.NewClient(rpcclient)
+	c.endpoints = []string{endpoint}
+	c.healthCheckInterval = DefaultHealthCheckInterval
+	c.maxHeadLag = DefaultMaxHeadLag
+	c.batchSize = DefaultBatchSize
+	c.confirmBatchTTL = DefaultConfirmBatchTTL
+	c.headPollInterval = DefaultHeadPollInterval
+	c.multicallAddress = DefaultMulticallAddress
+	c.multicallChunkSize = DefaultMulticallChunkSize
+	c.create2Deployer = DefaultCreate2Deployer
+	c.logRangeLimit = DefaultLogRangeLimit
+	c.retryPolicy = DefaultRetryPolicy
+	c.retryPolicies = map[string]RetryPolicy{MethodSendRawTransaction: DefaultSendRetryPolicy}
 	c.GasPrice = big.NewInt(int64(DefaultGasPrice))
 	c.timeout = DefaultTimeout
 	c.tipCapCashTTL = DefaultTipCapCashTTL
 	c.baseFeeCashTTL = DefaultBaseFeeCashTTL
+	c.signedNonceTTL = DefaultSignedNonceTTL
 	c.nonceCash = &NonceCash{nonces: lru.NewCache(1024, 0)}
 	c.queueSize = DefaultConfirmerQueueSize
 	c.logger = DefaultLogger
 	c.syncSendTimeout = DefaultSyncSendTimeout
 	c.syncSendConfirmInterval = DefaultSyncSendConfirmInterval
 
-	if c.chainID, err = c.ethclient.ChainID(ctx); err != nil {
-		err = errors.Wrap(err, "failed to get chain id")
+	for i := range opts {
+		opts[i].Apply(&c)
+	}
+
+	timeoutDuration = time.Duration(time.Duration(c.timeout) * time.Second)
+	syncSendTimeoutDuration = time.Duration(time.Duration(c.syncSendTimeout) * time.Second)
+	syncSendConfirmIntervalDuration = time.Duration(time.Duration(c.syncSendConfirmInterval) * time.Millisecond)
+
+	cfg := poolConfig{
+		maxLag:     c.maxHeadLag,
+		maxHeadAge: time.Duration(c.maxHeadAge) * time.Second,
+		minPeers:   c.minPeerCount,
+		logger:     c.logger,
+	}
+	if c.pool, err = newNodePool(ctx, c.endpoints, cfg); err != nil {
 		return
 	}
+	c.pool.retryPolicy = c.retryPolicy
+	c.pool.retryPolicies = c.retryPolicies
+	c.pool.limiter = newRateLimiter(c.readRateLimit, c.readBurst, c.writeRateLimit, c.writeBurst)
 
-	for i := range opts {
-		opts[i].Apply(&c)
+	if c.chainID, err = c.pool.ChainID(ctx); err != nil {
+		err = errors.Wrap(err, "failed to get chain id")
+		return
 	}
 
 	c.tipCash = &TipCapCash{ttl: c.tipCapCashTTL}
 	c.baseFeeCash = &BaseFeeCash{ttl: c.baseFeeCashTTL}
+	c.receipts = &receiptBatch{ttl: time.Duration(c.confirmBatchTTL) * time.Millisecond}
+	c.heads = newHeadWatcher(time.Duration(c.headPollInterval) * time.Millisecond)
+	c.backend = &ContractBackend{client: &c, ttl: c.signedNonceTTL, signed: make(map[common.Hash]signedNonce)}
+	c.multicall = &multicaller{address: c.multicallAddress, chunkSize: c.multicallChunkSize}
 
 	confirmer := confirm.NewConfirmer(&c, c.queueSize, append([]confirm.Opt{
 		confirm.WithWorkers(1),
@@ -94,10 +143,6 @@ func NewClient(ctx context.Context, endpoint string, cfmOpts []confirm.Opt, opts
 	c.unconfirmedTx = &safeMap{item: make(map[string]struct{})}
 	c.sentTx = &safeMap{item: make(map[string]struct{})}
 
-	timeoutDuration = time.Duration(time.Duration(c.timeout) * time.Second)
-	syncSendTimeoutDuration = time.Duration(time.Duration(c.syncSendTimeout) * time.Second)
-	syncSendConfirmIntervalDuration = time.Duration(time.Duration(c.syncSendConfirmInterval) * time.Millisecond)
-
 	return
 }
 
@@ -105,12 +150,25 @@ func (c *Client) Start() {
 	ctx, cancel := context.WithCancel(context.Background())
 	c.cancel = cancel
 
+	go c.pool.run(ctx, time.Duration(c.healthCheckInterval)*time.Millisecond)
+
+	go c.heads.run(ctx, c)
+	go c.heads.confirmOnHeads(ctx, c)
+
 	c.confirmer.Start(ctx)
 }
 
 func (c *Client) Stop() {
 	c.confirmer.Close(c.cancel)
-	c.ethclient.Close()
+	c.pool.Close()
+}
+
+func (c *Client) ChainID() *big.Int {
+	return new(big.Int).Set(c.chainID)
+}
+
+func (c *Client) RateLimitStats() RateLimitStats {
+	return c.pool.limiter.stats()
 }
 
 func (c *Client) Nonce(ctx context.Context, priv string) (nonce uint64, err error) {
@@ -121,14 +179,17 @@ func (c *Client) Nonce(ctx context.Context, priv string) (nonce uint64, err erro
 	}
 
 	account := crypto.PubkeyToAddress(privKey.PublicKey)
-	nonce, err = c.ethclient.NonceAt(ctx, account, nil)
+	nonce, err = c.pool.NonceAt(ctx, account, nil)
 	return
 }
 
 func (c *Client) SendTx(ctx context.Context, tx interface{}) (string, error) {
-	signedTx := tx.(*types.Transaction)
+	signedTx, ok := tx.(*types.Transaction)
+	if !ok {
+		return "", errors.Errorf("unexpected tx type(=%T)", tx)
+	}
 
-	if err := c.ethclient.SendTransaction(ctx, signedTx); err != nil {
+	if err := c.pool.SendTransaction(ctx, signedTx); err != nil {
 		return "", errors.Wrap(err, "err SendTransaction")
 	}
 
@@ -136,41 +197,70 @@ func (c *Client) SendTx(ctx context.Context, tx interface{}) (string, error) {
 }
 
 func (c *Client) AsyncSend(ctx context.Context, priv string, to *common.Address, amount *big.Int, input []byte, gasLimit uint64) (string, error) {
+	if !c.pool.isHealthy() {
+		return "", ErrNoHealthyNode
+	}
+
 	timeoutCtx, cancel := context.WithTimeout(ctx, timeoutDuration)
 	defer cancel()
 
-	tx, nonce, err := c.sinedTx(timeoutCtx, priv, to, amount, input, gasLimit)
+	tx, from, err := c.sinedTx(timeoutCtx, priv, to, amount, input, gasLimit)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to sign tx")
 	}
 
+	if c.preflight {
+		if err = c.preflightTx(timeoutCtx, tx); err != nil {
+			_ = c.nonceCash.AddFailedNonce(ctx, from, tx.Nonce())
+			return "", err
+		}
+	}
+
 	hash, err := c.SendTx(timeoutCtx, tx)
 	if err != nil {
-		_ = c.nonceCash.AddFailedNonce(ctx, priv, nonce)
+		_ = c.nonceCash.AddFailedNonce(ctx, from, tx.Nonce())
 	}
 
 	return hash, err
 }
 
 func (c *Client) SyncSend(ctx context.Context, priv string, to *common.Address, amount *big.Int, input []byte, gasLimit uint64) (hash string, err error) {
+	if !c.pool.isHealthy() {
+		err = ErrNoHealthyNode
+		return
+	}
+
 	timeoutCtx, cancel := context.WithTimeout(ctx, timeoutDuration)
 	defer cancel()
 
-	tx, nonce, err := c.sinedTx(timeoutCtx, priv, to, amount, input, gasLimit)
+	tx, from, err := c.sinedTx(timeoutCtx, priv, to, amount, input, gasLimit)
 	if err != nil {
 		err = errors.Wrap(err, "failed to sign tx")
 		return
 	}
 
+	if c.preflight {
+		if err = c.preflightTx(timeoutCtx, tx); err != nil {
+			_ = c.nonceCash.AddFailedNonce(ctx, from, tx.Nonce())
+			return
+		}
+	}
+
 	hash = tx.Hash().Hex()
 
 	if err = c.confirmer.EnqueueTx(timeoutCtx, tx); err != nil {
-		_ = c.nonceCash.AddFailedNonce(ctx, priv, nonce)
+		_ = c.nonceCash.AddFailedNonce(ctx, from, tx.Nonce())
 		err = errors.Wrapf(err, "failed to enqueue tx(%v)", tx)
 		return
 	}
 
-	timeoutCtx, cancel = context.WithTimeout(ctx, syncSendTimeoutDuration)
+	err = c.waitConfirmed(ctx, hash)
+	return
+}
+
+// waitConfirmed waits until the enqueued tx is confirmed by the confirmer
+func (c *Client) waitConfirmed(ctx context.Context, hash string) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, syncSendTimeoutDuration)
 	defer cancel()
 
 	timer := time.NewTicker(syncSendConfirmIntervalDuration)
@@ -179,28 +269,29 @@ func (c *Client) SyncSend(ctx context.Context, priv string, to *common.Address,
 	for {
 		select {
 		case <-timeoutCtx.Done():
-			err = ErrSyncSendTimeout
-			return
+			return ErrSyncSendTimeout
+		case <-c.heads.next():
 		case <-timer.C:
-			if !c.sentTx.has(hash) {
-				continue
-			}
-			if !c.unconfirmedTx.has(hash) {
-				c.sentTx.delete(hash)
-				return
-			}
+		}
+
+		if !c.sentTx.has(hash) {
+			continue
+		}
+		if !c.unconfirmedTx.has(hash) {
+			c.sentTx.delete(hash)
+			return nil
 		}
 	}
 }
 
 func (c *Client) ConfirmTx(ctx context.Context, hash string, confirmationBlocks uint64) error {
-	recept, err := c.Receipt(ctx, hash)
+	recept, block, err := c.receipts.get(ctx, c, hash)
 	if err != nil {
 		if errors.Is(err, ethereum.NotFound) {
 			return confirm.ErrTxNotFound
 		}
 
-		return errors.Wrap(err, "err TransactionReceipt")
+		return err
 	}
 
 	if recept.Status != 1 {
@@ -208,11 +299,6 @@ func (c *Client) ConfirmTx(ctx context.Context, hash string, confirmationBlocks
 		return confirm.ErrTxFailed
 	}
 
-	block, err := c.LatestBlockNumber(ctx)
-	if err != nil {
-		return errors.Wrap(err, "err LatestBlockNumber")
-	}
-
 	if recept.BlockNumber.Uint64()+confirmationBlocks > block {
 		return confirm.ErrTxConfirmPending
 	}
@@ -228,15 +314,15 @@ func (c *Client) EnqueueTxHash(ctx context.Context, hash string) error {
 }
 
 func (c *Client) Receipt(ctx context.Context, hash string) (*types.Receipt, error) {
-	return c.ethclient.TransactionReceipt(ctx, common.HexToHash(hash))
+	return c.pool.TransactionReceipt(ctx, common.HexToHash(hash))
 }
 
 func (c *Client) BalanceOf(ctx context.Context, account common.Address) (*big.Int, error) {
-	return c.ethclient.BalanceAt(ctx, account, nil)
+	return c.pool.BalanceAt(ctx, account, nil)
 }
 
 func (c *Client) LatestBlockNumber(ctx context.Context) (uint64, error) {
-	header, err := c.ethclient.HeaderByNumber(ctx, nil)
+	header, err := c.pool.HeaderByNumber(ctx, nil)
 	if err != nil {
 		return 0, err
 	}
@@ -248,14 +334,14 @@ func (c *Client) QueryContract(ctx context.Context, to common.Address, input []b
 		msg  = ethereum.CallMsg{To: &to, Data: input}
 		code []byte
 	)
-	if output, err = c.ethclient.CallContract(ctx, msg, nil); err != nil {
+	if output, err = c.pool.CallContract(ctx, msg, nil); err != nil {
 		err = errors.Wrapf(err, "failed to call contract(=%s)", to.String())
 		return
 	}
 
 	if len(output) == 0 {
 		// Make sure we have a contract to operate on, and bail out otherwise.
-		if code, err = c.ethclient.CodeAt(ctx, to, nil); err != nil {
+		if code, err = c.pool.CodeAt(ctx, to, nil); err != nil {
 			err = errors.Wrap(err, "at ethclient.CodeAt")
 			return
 		} else if len(code) == 0 {
@@ -277,15 +363,20 @@ func (c *Client) estimateGasLimit(ctx context.Context, from common.Address, to *
 		Value:     value,
 		Data:      input,
 	}
-	return c.ethclient.EstimateGas(ctx, msg)
+	return c.pool.EstimateGas(ctx, msg)
 }
 
 func (c *Client) NonceCash(ctx context.Context, priv string) (uint64, error) {
-	return c.nonceCash.Current(ctx, priv)
+	privKey, err := crypto.HexToECDSA(priv)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to parse private key")
+	}
+	return c.nonceCash.Current(ctx, crypto.PubkeyToAddress(privKey.PublicKey))
 }
 
 func (c *Client) isSupportEIP1559(ctx context.Context) (bool, error) {
-	if _, err := c.ethclient.SuggestGasTipCap(ctx); err != nil {
+	// the tip is cached, so that the node is not probed on every send
+	if _, err := c.tipCash.GasTipCap(ctx, c); err != nil {
 		if strings.Contains(err.Error(), "eth_maxPriorityFeePerGas does not exist") {
 			return false, nil
 		} else {
@@ -295,44 +386,58 @@ func (c *Client) isSupportEIP1559(ctx context.Context) (bool, error) {
 	return true, nil
 }
 
-func (c *Client) sinedTx(ctx context.Context, priv string, to *common.Address, amount *big.Int, input []byte, gasLimit uint64) (*types.Transaction, uint64, error) {
+// sinedTx returns the signed tx with the sender, the nonce is given back to the nonce cache on failure
+func (c *Client) sinedTx(ctx context.Context, priv string, to *common.Address, amount *big.Int, input []byte, gasLimit uint64) (*types.Transaction, common.Address, error) {
 	privKey, err := crypto.HexToECDSA(priv)
 	if err != nil {
-		return nil, 0, errors.Wrap(err, "failed to get nonce")
+		return nil, common.Address{}, errors.Wrap(err, "failed to get nonce")
+	}
+	from := crypto.PubkeyToAddress(privKey.PublicKey)
+
+	n, err := c.nonceCash.Nonce(ctx, from, c)
+	if err != nil {
+		return nil, from, errors.Wrap(err, "failed to get nonce")
+	}
+
+	txdata, err := c.buildTxData(ctx, from, n, to, amount, input, gasLimit)
+	if err != nil {
+		_ = c.nonceCash.AddFailedNonce(ctx, from, n)
+		return nil, from, err
 	}
 
-	n, err := c.nonceCash.Nonce(ctx, priv, c)
+	tx, err := types.SignNewTx(privKey, types.NewLondonSigner(c.chainID), txdata)
 	if err != nil {
-		return nil, 0, errors.Wrap(err, "failed to get nonce")
+		_ = c.nonceCash.AddFailedNonce(ctx, from, n)
+		return nil, from, errors.Wrap(err, "at types.SignNewTx")
 	}
 
+	return tx, from, nil
+}
+
+func (c *Client) buildTxData(ctx context.Context, from common.Address, n uint64, to *common.Address, amount *big.Int, input []byte, gasLimit uint64) (types.TxData, error) {
 	isDynamic, err := c.isSupportEIP1559(ctx)
 	if err != nil {
-		return nil, 0, errors.Wrap(err, "failed to check eip1559 support")
+		return nil, errors.Wrap(err, "failed to check eip1559 support")
 	}
 
-	var (
-		txdata types.TxData
-		signer = types.NewLondonSigner(c.chainID)
-	)
 	if isDynamic {
 		tip, err := c.tipCash.GasTipCap(ctx, c)
 		if err != nil {
-			return nil, 0, errors.Wrap(err, "failed to get GasTipCap")
+			return nil, errors.Wrap(err, "failed to get GasTipCap")
 		}
 
 		gasFee, err := c.baseFeeCash.GasFee(ctx, c, tip)
 		if err != nil {
-			return nil, 0, errors.Wrap(err, "failed to get FeeCap")
+			return nil, errors.Wrap(err, "failed to get FeeCap")
 		}
 
 		if gasLimit == 0 {
-			auth := bind.NewKeyedTransactor(privKey)
-			if gasLimit, err = c.estimateGasLimit(ctx, auth.From, to, amount, input, tip, gasFee); err != nil {
-				return nil, 0, errors.Wrap(err, "failed to estimate gas")
+			if gasLimit, err = c.estimateGasLimit(ctx, from, to, amount, input, tip, gasFee); err != nil {
+				return nil, errors.Wrap(err, "failed to estimate gas")
 			}
 		}
-		txdata = &types.DynamicFeeTx{
+		c.logger.Debug().Msgf("dynamic tx contents nonce=%d, gasTip=%s, gasFee=%s, gas=%d, to=%s, value=%s, data=%s", n, tip.String(), gasFee.String(), gasLimit, to.String(), amount.String(), string(input))
+		return &types.DynamicFeeTx{
 			ChainID:    c.chainID,
 			Nonce:      n,
 			GasTipCap:  tip,
@@ -342,32 +447,23 @@ func (c *Client) sinedTx(ctx context.Context, priv string, to *common.Address, a
 			Value:      amount,
 			Data:       input,
 			AccessList: nil,
-		}
-		c.logger.Debug().Msgf("dynamic tx contents nonce=%d, gasTip=%s, gasFee=%s, gas=%d, to=%s, value=%s, data=%s", n, tip.String(), gasFee.String(), gasLimit, to.String(), amount.String(), string(input))
-	} else {
-		if gasLimit == 0 {
-			auth := bind.NewKeyedTransactor(privKey)
-			if gasLimit, err = c.estimateGasLimit(ctx, auth.From, to, amount, input, nil, nil); err != nil {
-				return nil, 0, errors.Wrap(err, "failed to estimate gas")
-			}
-		}
-		c.logger.Debug().Msgf("legacy tx contents nonce=%d, gasPrice=%s, gas=%d, to=%s, value=%s, data=%s", n, c.GasPrice.String(), gasLimit, to.String(), amount.String(), string(input))
-		txdata = &types.LegacyTx{
-			Nonce:    n,
-			GasPrice: c.GasPrice,
-			Gas:      gasLimit,
-			To:       to,
-			Value:    amount,
-			Data:     input,
-		}
+		}, nil
 	}
 
-	tx, err := types.SignNewTx(privKey, signer, txdata)
-	if err != nil {
-		return nil, 0, errors.Wrap(err, "at types.SignNewTx")
+	if gasLimit == 0 {
+		if gasLimit, err = c.estimateGasLimit(ctx, from, to, amount, input, nil, nil); err != nil {
+			return nil, errors.Wrap(err, "failed to estimate gas")
+		}
 	}
-
-	return tx, n, nil
+	c.logger.Debug().Msgf("legacy tx contents nonce=%d, gasPrice=%s, gas=%d, to=%s, value=%s, data=%s", n, c.GasPrice.String(), gasLimit, to.String(), amount.String(), string(input))
+	return &types.LegacyTx{
+		Nonce:    n,
+		GasPrice: c.GasPrice,
+		Gas:      gasLimit,
+		To:       to,
+		Value:    amount,
+		Data:     input,
+	}, nil
 }
 
 func (c *Client) afterTxSent(hash string) error {
@@ -382,6 +478,7 @@ func (c *Client) afterTxConfirmed(hash string) error {
 	if c.unconfirmedTx.has(hash) {
 		c.unconfirmedTx.delete(hash)
 	}
+	c.heads.notify()
 	return nil
 }
 
@@ -390,5 +487,6 @@ func (c *Client) errHandle(hash string, err error) {
 	if c.unconfirmedTx.has(hash) {
 		c.unconfirmedTx.delete(hash)
 	}
+	c.heads.notify()
 	return
 }
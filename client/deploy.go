@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+var (
+	// DefaultCreate2Deployer is the deterministic deployment proxy, which deploys the calldata of salt ++ initcode by CREATE2
+	DefaultCreate2Deployer = common.HexToAddress("0x4e59b44847b379578588920cA78FbF26c0B4956C")
+
+	ErrDeployFailed = errors.New("deployment failed")
+	ErrNoDeployer   = errors.New("create2 deployer is not deployed")
+)
+
+// Deploy deploys the contract by SyncSend, and returns the address with the receipt.
+// The parsed is used to pack the constructor args, and can be nil when the constructor takes no args.
+func (c *Client) Deploy(ctx context.Context, priv string, parsed *abi.ABI, bytecode []byte, args ...interface{}) (common.Address, *types.Receipt, error) {
+	initCode, err := deployInitCode(parsed, bytecode, args...)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	receipt, err := c.sendDeploy(ctx, priv, nil, initCode)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	return receipt.ContractAddress, receipt, nil
+}
+
+// Create2Address pre-computes the address of the contract deployed by the deployer with the salt
+func Create2Address(deployer common.Address, salt [32]byte, initCode []byte) common.Address {
+	return crypto.CreateAddress2(deployer, salt, crypto.Keccak256(initCode))
+}
+
+// DeployCreate2 deploys the contract at the deterministic address through the create2 deployer.
+// When the contract is already deployed, the deployment is skipped and the receipt is nil.
+func (c *Client) DeployCreate2(ctx context.Context, priv string, salt [32]byte, parsed *abi.ABI, bytecode []byte, args ...interface{}) (common.Address, *types.Receipt, error) {
+	initCode, err := deployInitCode(parsed, bytecode, args...)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	addr := Create2Address(c.create2Deployer, salt, initCode)
+
+	code, err := c.pool.CodeAt(ctx, addr, nil)
+	if err != nil {
+		return common.Address{}, nil, errors.Wrap(err, "at ethclient.CodeAt")
+	}
+	if len(code) > 0 {
+		c.logger.Info().Msgf("contract(=%s) is already deployed, skip deployment", addr)
+		return addr, nil, nil
+	}
+
+	if code, err = c.pool.CodeAt(ctx, c.create2Deployer, nil); err != nil {
+		return common.Address{}, nil, errors.Wrap(err, "at ethclient.CodeAt")
+	} else if len(code) == 0 {
+		return common.Address{}, nil, errors.Wrapf(ErrNoDeployer, "deployer=%s", c.create2Deployer)
+	}
+
+	receipt, err := c.sendDeploy(ctx, priv, &c.create2Deployer, append(salt[:], initCode...))
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	// the deployer does not revert even if the creation fails
+	if code, err = c.pool.CodeAt(ctx, addr, nil); err != nil {
+		return common.Address{}, nil, errors.Wrap(err, "at ethclient.CodeAt")
+	} else if len(code) == 0 {
+		return common.Address{}, nil, errors.Wrapf(ErrDeployFailed, "no code at %s, tx=%s", addr, receipt.TxHash)
+	}
+
+	return addr, receipt, nil
+}
+
+func deployInitCode(parsed *abi.ABI, bytecode []byte, args ...interface{}) ([]byte, error) {
+	if parsed == nil {
+		if len(args) > 0 {
+			return nil, errors.New("abi is required to pack the constructor args")
+		}
+		return bytecode, nil
+	}
+
+	input, err := parsed.Pack("", args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pack constructor args")
+	}
+	return append(append([]byte{}, bytecode...), input...), nil
+}
+
+func (c *Client) sendDeploy(ctx context.Context, priv string, to *common.Address, data []byte) (*types.Receipt, error) {
+	hash, err := c.SyncSend(ctx, priv, to, nil, data, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to send deployment")
+	}
+
+	receipt, err := c.Receipt(ctx, hash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get receipt of %s", hash)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, errors.Wrapf(ErrDeployFailed, "tx=%s", hash)
+	}
+
+	return receipt, nil
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreate2Address(t *testing.T) {
+	// the examples of EIP-1014
+	require.Equal(t,
+		common.HexToAddress("0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38"),
+		Create2Address(common.Address{}, [32]byte{}, []byte{0x00}))
+	require.Equal(t,
+		common.HexToAddress("0xB928f69Bb1D91Cd65274e3c79d8986362984fDA3"),
+		Create2Address(common.HexToAddress("0xdeadbeef00000000000000000000000000000000"), [32]byte{}, []byte{0x00}))
+}
+
+func TestDeployCreate2Skip(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		f        = newFakeNode(1010, 10)
+		salt     = [32]byte{0x01}
+		bytecode = []byte{0x60, 0x00}
+		deployed = Create2Address(DefaultCreate2Deployer, salt, bytecode)
+	)
+	defer f.Close()
+
+	f.handle("eth_getCode", func(params []json.RawMessage) (interface{}, error) {
+		var addr common.Address
+		_ = json.Unmarshal(params[0], &addr)
+		if addr == deployed {
+			return hexutil.Bytes{0x01}, nil
+		}
+		return hexutil.Bytes{}, nil
+	})
+
+	c, err := NewClient(ctx, f.URL, nil)
+	require.NoError(t, err)
+
+	addr, receipt, err := c.DeployCreate2(ctx, TestPrivKey, salt, nil, bytecode)
+	require.NoError(t, err)
+	require.Equal(t, deployed, addr)
+	require.Nil(t, receipt)
+
+	_, _, err = c.DeployCreate2(ctx, TestPrivKey, [32]byte{0x02}, nil, bytecode)
+	require.ErrorIs(t, err, ErrNoDeployer)
+	require.Equal(t, 0, f.called(MethodSendRawTransaction))
+}
@@ -0,0 +1,333 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// TransferEventID is the topic of Transfer(address,address,uint256)
+var TransferEventID = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// Deposit is the native transfer when the Token is zero address, otherwise the ERC20 transfer.
+// The Removed deposit is reorged out after delivered, which should be reverted.
+// The native deposits delivered before the restart are not removed, when the node already dropped the orphan block.
+type Deposit struct {
+	Token    common.Address
+	From     common.Address
+	To       common.Address
+	Amount   *big.Int
+	TxHash   common.Hash
+	LogIndex uint
+	Block    BlockPointer
+	Removed  bool
+	// the native transfer by the internal call of the contract, such as the contract wallet
+	Internal bool
+}
+
+func (d *Deposit) IsNative() bool {
+	return d.Token == (common.Address{})
+}
+
+type DepositWatcherConfig struct {
+	// the first block scanned when the cursor is empty
+	From uint64
+	// the deposits are delivered when the head is Confirmations blocks ahead, the same as ConfirmTx
+	Confirmations uint64
+	// the ERC20 tokens to watch, and the token deposits are not watched when empty
+	Tokens []common.Address
+	// watches the Transfer of any contract instead of the Tokens, which is unsafe,
+	// as anyone can deploy the contract emitting the fake Transfer to the watched addresses
+	AllTokens bool
+	// the watcher starts from the beginning when nil
+	Cursor Cursor
+	// detects the native transfers by the internal calls with debug_traceBlockByHash,
+	// which requires the node to enable the debug namespace
+	TraceInternal bool
+}
+
+// DepositWatcher scans the blocks for the native transfers and the ERC20 Transfer logs to the watched addresses.
+// Note that the native transfers by internal calls of the contracts are detected only with TraceInternal.
+type DepositWatcher struct {
+	client        *Client
+	stream        *BlockStream
+	traceInternal bool
+
+	sync.Mutex
+	watched map[common.Address]struct{}
+	// the deposits of the tracked blocks to be removed on reorg
+	delivered map[common.Hash]deliveredBlock
+}
+
+type deliveredBlock struct {
+	number   uint64
+	deposits []Deposit
+}
+
+func (c *Client) NewDepositWatcher(cfg DepositWatcherConfig, addrs ...common.Address) (*DepositWatcher, error) {
+	var logs *ethereum.FilterQuery
+	if cfg.AllTokens {
+		logs = &ethereum.FilterQuery{Topics: [][]common.Hash{{TransferEventID}}}
+	} else if len(cfg.Tokens) > 0 {
+		logs = &ethereum.FilterQuery{Addresses: cfg.Tokens, Topics: [][]common.Hash{{TransferEventID}}}
+	}
+
+	stream, err := c.NewBlockStream(BlockStreamConfig{
+		From:          cfg.From,
+		Confirmations: cfg.Confirmations,
+		Logs:          logs,
+		Cursor:        cfg.Cursor,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	w := &DepositWatcher{
+		client:        c,
+		stream:        stream,
+		traceInternal: cfg.TraceInternal,
+		watched:       make(map[common.Address]struct{}),
+		delivered:     make(map[common.Hash]deliveredBlock),
+	}
+	w.Watch(addrs...)
+	return w, nil
+}
+
+func (w *DepositWatcher) Watch(addrs ...common.Address) {
+	w.Lock()
+	defer w.Unlock()
+	for _, addr := range addrs {
+		w.watched[addr] = struct{}{}
+	}
+}
+
+func (w *DepositWatcher) Unwatch(addrs ...common.Address) {
+	w.Lock()
+	defer w.Unlock()
+	for _, addr := range addrs {
+		delete(w.watched, addr)
+	}
+}
+
+func (w *DepositWatcher) isWatched(addr common.Address) bool {
+	w.Lock()
+	defer w.Unlock()
+	_, ok := w.watched[addr]
+	return ok
+}
+
+// Run delivers the deposits until the ctx is done or the handler fails.
+// The deposits of a block are redelivered on the next run, when the handler fails in the middle of them.
+func (w *DepositWatcher) Run(ctx context.Context, handler func(Deposit) error) error {
+	return w.stream.Run(ctx, func(ev BlockEvent) error {
+		deposits, err := w.deposits(ctx, ev)
+		if err != nil {
+			return Retryable(err)
+		}
+
+		for i := range deposits {
+			if err = handler(deposits[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RunChan is Run delivering the deposits to the ch
+func (w *DepositWatcher) RunChan(ctx context.Context, ch chan<- Deposit) error {
+	return w.Run(ctx, func(d Deposit) error {
+		select {
+		case ch <- d:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+func (w *DepositWatcher) deposits(ctx context.Context, ev BlockEvent) ([]Deposit, error) {
+	w.Lock()
+	block, ok := w.delivered[ev.Block.Hash]
+	w.Unlock()
+
+	if ev.Type == Removed {
+		delivered := block.deposits
+		if !ok {
+			// delivered before the restart
+			var err error
+			delivered, err = w.scan(ctx, ev)
+			if errors.Is(err, ethereum.NotFound) {
+				// the node dropped the orphan block, so only the deposits of the removed logs are recovered
+				w.client.logger.Warn().Msgf("removed block(=%d, %s) is not found, the native deposits of it are not removed", ev.Block.Number, ev.Block.Hash)
+				delivered, err = w.scanTokens(ev.Block, ev.Logs), nil
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to rescan removed block(=%d)", ev.Block.Number)
+			}
+		}
+
+		removed := make([]Deposit, len(delivered))
+		for i := range delivered {
+			removed[i] = delivered[i]
+			removed[i].Removed = true
+		}
+
+		w.Lock()
+		delete(w.delivered, ev.Block.Hash)
+		w.Unlock()
+		return removed, nil
+	}
+
+	deposits, err := w.scan(ctx, ev)
+	if err != nil {
+		return nil, err
+	}
+
+	w.Lock()
+	w.delivered[ev.Block.Hash] = deliveredBlock{number: ev.Block.Number, deposits: deposits}
+	for hash, b := range w.delivered {
+		if b.number+uint64(w.stream.cfg.MaxReorgDepth) < ev.Block.Number {
+			delete(w.delivered, hash)
+		}
+	}
+	w.Unlock()
+
+	return deposits, nil
+}
+
+func (w *DepositWatcher) scan(ctx context.Context, ev BlockEvent) ([]Deposit, error) {
+	block, err := w.client.pool.BlockByHash(ctx, ev.Block.Hash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get block(=%d)", ev.Block.Number)
+	}
+
+	var native []Deposit
+	if w.traceInternal {
+		native, err = w.scanInternal(ctx, ev.Block, block)
+	} else {
+		native, err = w.scanNative(ctx, ev.Block, block)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return append(native, w.scanTokens(ev.Block, ev.Logs)...), nil
+}
+
+// scanInternal finds both the top level and the internal native transfers from the traces,
+// which exclude the reverted ones without the receipts
+func (w *DepositWatcher) scanInternal(ctx context.Context, pointer BlockPointer, block *types.Block) ([]Deposit, error) {
+	if len(block.Transactions()) == 0 {
+		return nil, nil
+	}
+
+	frames, err := w.client.TraceBlock(ctx, pointer.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(frames) != len(block.Transactions()) {
+		return nil, errors.Errorf("unexpected number of traces of block(=%d), want=%d, got=%d", pointer.Number, len(block.Transactions()), len(frames))
+	}
+
+	var deposits []Deposit
+	for i, frame := range frames {
+		for _, transfer := range frame.ValueTransfers() {
+			if !w.isWatched(transfer.To) {
+				continue
+			}
+			deposits = append(deposits, Deposit{
+				From:     transfer.From,
+				To:       transfer.To,
+				Amount:   transfer.Value,
+				TxHash:   block.Transactions()[i].Hash(),
+				Block:    pointer,
+				Internal: transfer.Depth > 0,
+			})
+		}
+	}
+	return deposits, nil
+}
+
+func (w *DepositWatcher) scanNative(ctx context.Context, pointer BlockPointer, block *types.Block) ([]Deposit, error) {
+	var (
+		deposits []Deposit
+		signer   = types.LatestSignerForChainID(w.client.chainID)
+	)
+	for _, tx := range block.Transactions() {
+		if tx.To() == nil || tx.Value().Sign() == 0 || !w.isWatched(*tx.To()) {
+			continue
+		}
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get sender of tx(=%s)", tx.Hash())
+		}
+		deposits = append(deposits, Deposit{
+			From:   from,
+			To:     *tx.To(),
+			Amount: tx.Value(),
+			TxHash: tx.Hash(),
+			Block:  pointer,
+		})
+	}
+	if len(deposits) == 0 {
+		return nil, nil
+	}
+
+	// the value of the reverted tx is not transferred
+	var (
+		receipts = make([]*types.Receipt, len(deposits))
+		b        = NewBatch()
+	)
+	for i := range deposits {
+		b.Receipt(deposits[i].TxHash, &receipts[i])
+	}
+	errs, err := w.client.Batch(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+
+	succeeded := deposits[:0]
+	for i := range deposits {
+		if errs[i] != nil {
+			return nil, errors.Wrapf(errs[i], "failed to get receipt of tx(=%s)", deposits[i].TxHash)
+		}
+		if receipts[i] == nil {
+			return nil, errors.Wrapf(ethereum.NotFound, "receipt of tx(=%s)", deposits[i].TxHash)
+		}
+		if receipts[i].Status == types.ReceiptStatusSuccessful {
+			succeeded = append(succeeded, deposits[i])
+		}
+	}
+	return succeeded, nil
+}
+
+func (w *DepositWatcher) scanTokens(pointer BlockPointer, logs []types.Log) []Deposit {
+	var deposits []Deposit
+	for _, l := range logs {
+		// the Transfer of ERC721 has the indexed tokenId
+		if len(l.Topics) != 3 || len(l.Data) != 32 {
+			continue
+		}
+		to := common.BytesToAddress(l.Topics[2].Bytes())
+		if !w.isWatched(to) {
+			continue
+		}
+		deposits = append(deposits, Deposit{
+			Token:    l.Address,
+			From:     common.BytesToAddress(l.Topics[1].Bytes()),
+			To:       to,
+			Amount:   new(big.Int).SetBytes(l.Data),
+			TxHash:   l.TxHash,
+			LogIndex: l.Index,
+			Block:    pointer,
+		})
+	}
+	return deposits
+}
@@ -0,0 +1,229 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepositWatcher(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		f       = newFakeNode(1010, 10)
+		chain   = newFakeChain()
+		watched = common.HexToAddress(TestAccount2)
+		token   = common.HexToAddress("0x01")
+		from    = common.HexToAddress(TestAccount)
+	)
+	defer f.Close()
+
+	privKey, err := crypto.HexToECDSA(TestPrivKey)
+	require.NoError(t, err)
+	signer := types.LatestSignerForChainID(big.NewInt(1010))
+	newTx := func(nonce uint64, to common.Address, value int64, data []byte) *types.Transaction {
+		tx, err := types.SignNewTx(privKey, signer, &types.LegacyTx{Nonce: nonce, To: &to, Value: big.NewInt(value), Gas: 21000, GasPrice: big.NewInt(1), Data: data})
+		require.NoError(t, err)
+		return tx
+	}
+
+	deposit := newTx(0, watched, 5, nil)
+	chain.txs[2] = []*types.Transaction{
+		deposit,
+		newTx(1, watched, 6, []byte{0xff}), // reverted
+		newTx(2, from, 7, nil),             // not watched
+	}
+	chain.logs[3] = []*types.Log{
+		{Address: token, Topics: []common.Hash{TransferEventID, from.Hash(), watched.Hash()}, Data: common.BigToHash(big.NewInt(8)).Bytes(), Index: 1},
+		// ERC721 Transfer
+		{Address: token, Topics: []common.Hash{TransferEventID, from.Hash(), watched.Hash(), common.BigToHash(big.NewInt(9))}, Index: 2},
+	}
+	chain.fork(0, 4, 0xa)
+	chain.serve(f)
+
+	f.handle(MethodGetTransactionReceipt, func(params []json.RawMessage) (interface{}, error) {
+		var hash common.Hash
+		_ = json.Unmarshal(params[0], &hash)
+		status := types.ReceiptStatusSuccessful
+		if hash != deposit.Hash() {
+			status = types.ReceiptStatusFailed
+		}
+		return &types.Receipt{Status: status, TxHash: hash, BlockNumber: big.NewInt(2), Logs: []*types.Log{}}, nil
+	})
+
+	c, err := NewClient(ctx, f.URL, nil, WithHeadPollInterval(10))
+	require.NoError(t, err)
+
+	// the token deposits are not watched without the tokens
+	w, err := c.NewDepositWatcher(DepositWatcherConfig{From: 1, Confirmations: 1}, watched)
+	require.NoError(t, err)
+	require.Nil(t, w.stream.cfg.Logs)
+
+	w, err = c.NewDepositWatcher(DepositWatcherConfig{From: 1, Confirmations: 1, Tokens: []common.Address{token}}, watched)
+	require.NoError(t, err)
+
+	watch := func(n int) []Deposit {
+		var (
+			deposits    []Deposit
+			ctx, cancel = context.WithTimeout(ctx, 3*time.Second)
+		)
+		defer cancel()
+
+		err := w.Run(ctx, func(d Deposit) error {
+			if deposits = append(deposits, d); len(deposits) == n {
+				cancel()
+			}
+			return nil
+		})
+		require.ErrorIs(t, err, context.Canceled)
+		return deposits
+	}
+
+	deposits := watch(2)
+	require.True(t, deposits[0].IsNative())
+	require.Equal(t, from, deposits[0].From)
+	require.Equal(t, big.NewInt(5), deposits[0].Amount)
+	require.Equal(t, deposit.Hash(), deposits[0].TxHash)
+	require.Equal(t, uint64(2), deposits[0].Block.Number)
+
+	require.Equal(t, token, deposits[1].Token)
+	require.Equal(t, big.NewInt(8), deposits[1].Amount)
+	require.Equal(t, uint64(3), deposits[1].Block.Number)
+	require.False(t, deposits[1].Removed)
+
+	// the block 3 is reorged without the token transfer
+	chain.Lock()
+	chain.logs[3] = []*types.Log{}
+	chain.Unlock()
+	chain.fork(3, 5, 0xb)
+
+	deposits = watch(1)
+	require.True(t, deposits[0].Removed)
+	require.Equal(t, token, deposits[0].Token)
+	require.Equal(t, big.NewInt(8), deposits[0].Amount)
+}
+
+func TestDepositWatcherRestartDroppedBlock(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		f       = newFakeNode(1010, 10)
+		chain   = newFakeChain()
+		cursor  = NewFileCursor(filepath.Join(t.TempDir(), "cursor.json"))
+		watched = common.HexToAddress(TestAccount2)
+	)
+	defer f.Close()
+
+	privKey, err := crypto.HexToECDSA(TestPrivKey)
+	require.NoError(t, err)
+	deposit, err := types.SignNewTx(privKey, types.LatestSignerForChainID(big.NewInt(1010)), &types.LegacyTx{To: &watched, Value: big.NewInt(5), Gas: 21000, GasPrice: big.NewInt(1)})
+	require.NoError(t, err)
+
+	chain.txs[2] = []*types.Transaction{deposit}
+	chain.fork(0, 4, 0xa)
+	chain.serve(f)
+
+	f.handle(MethodGetTransactionReceipt, func(params []json.RawMessage) (interface{}, error) {
+		var hash common.Hash
+		_ = json.Unmarshal(params[0], &hash)
+		return &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: hash, BlockNumber: big.NewInt(2), Logs: []*types.Log{}}, nil
+	})
+
+	c, err := NewClient(ctx, f.URL, nil, WithHeadPollInterval(10))
+	require.NoError(t, err)
+
+	// a new watcher on each call, which forgets the delivered deposits like the restart
+	watch := func() Deposit {
+		w, err := c.NewDepositWatcher(DepositWatcherConfig{From: 1, Confirmations: 1, Cursor: cursor}, watched)
+		require.NoError(t, err)
+
+		ch := make(chan Deposit, 1)
+		ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		defer cancel()
+		go func() { _ = w.RunChan(ctx, ch) }()
+
+		select {
+		case d := <-ch:
+			return d
+		case <-ctx.Done():
+			require.FailNow(t, "timeout")
+		}
+		return Deposit{}
+	}
+
+	d := watch()
+	require.Equal(t, uint64(2), d.Block.Number)
+	orphan := d.Block.Hash
+
+	// the blocks from 2 are reorged, and the node dropped the orphans
+	chain.fork(2, 5, 0xb)
+	chain.Lock()
+	for hash, block := range chain.blocks {
+		if block.Extra()[0] == 0xa && block.NumberU64() >= 2 {
+			delete(chain.blocks, hash)
+		}
+	}
+	chain.Unlock()
+
+	// the removed blocks are skipped, and the deposit of the new block is delivered
+	d = watch()
+	require.False(t, d.Removed)
+	require.Equal(t, uint64(2), d.Block.Number)
+	require.NotEqual(t, orphan, d.Block.Hash)
+	require.Equal(t, deposit.Hash(), d.TxHash)
+}
+
+func TestDepositWatcherTraceInternal(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		f      = newFakeNode(1010, 10)
+		chain  = newFakeChain()
+		wallet = common.HexToAddress("0xe1245045b2e1d3645f2d9c1bde93e9dcf9a8901f")
+		payee  = common.HexToAddress("0x621d7e7aed9ad05ac08519da02bcb7aab8660f0d")
+	)
+	defer f.Close()
+
+	privKey, err := crypto.HexToECDSA(TestPrivKey)
+	require.NoError(t, err)
+	tx, err := types.SignNewTx(privKey, types.LatestSignerForChainID(big.NewInt(1010)), &types.LegacyTx{To: &wallet, Value: big.NewInt(0), Gas: 250000, GasPrice: big.NewInt(1)})
+	require.NoError(t, err)
+
+	chain.txs[1] = []*types.Transaction{tx}
+	chain.fork(0, 2, 0xa)
+	chain.serve(f)
+
+	fixture, err := os.ReadFile("testdata/trace_transaction.json")
+	require.NoError(t, err)
+	f.handle("debug_traceBlockByHash", func([]json.RawMessage) (interface{}, error) {
+		return []map[string]json.RawMessage{{"result": fixture}}, nil
+	})
+
+	c, err := NewClient(ctx, f.URL, nil, WithHeadPollInterval(10))
+	require.NoError(t, err)
+
+	w, err := c.NewDepositWatcher(DepositWatcherConfig{From: 1, Confirmations: 1, TraceInternal: true}, payee)
+	require.NoError(t, err)
+
+	ch := make(chan Deposit, 1)
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	go func() { _ = w.RunChan(ctx, ch) }()
+
+	select {
+	case d := <-ch:
+		require.True(t, d.Internal)
+		require.True(t, d.IsNative())
+		require.Equal(t, wallet, d.From)
+		require.Equal(t, big.NewInt(1e18), d.Amount)
+		require.Equal(t, tx.Hash(), d.TxHash)
+	case <-ctx.Done():
+		t.Fatal("timeout")
+	}
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	eip1271ABI = `[{"inputs":[{"internalType":"bytes32","name":"hash","type":"bytes32"},{"internalType":"bytes","name":"signature","type":"bytes"}],"name":"isValidSignature","outputs":[{"internalType":"bytes4","name":"magicValue","type":"bytes4"}],"stateMutability":"view","type":"function"}]`
+)
+
+var (
+	// EIP1271MagicValue is returned by isValidSignature of the contract when the signature is valid
+	EIP1271MagicValue = [4]byte{0x16, 0x26, 0xba, 0x7e}
+
+	isValidSignatureABI abi.ABI
+)
+
+func init() {
+	var err error
+	if isValidSignatureABI, err = abi.JSON(strings.NewReader(eip1271ABI)); err != nil {
+		panic(err)
+	}
+}
+
+// IsValidSignature asks the contract account whether the signature of the hash is valid by EIP-1271.
+// The revert of the contract is regarded as invalid.
+func (c *Client) IsValidSignature(ctx context.Context, contract common.Address, hash common.Hash, sig []byte) (bool, error) {
+	input, err := isValidSignatureABI.Pack("isValidSignature", hash, sig)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to pack isValidSignature")
+	}
+
+	output, err := c.QueryContract(ctx, contract, input)
+	if err != nil {
+		if toRevertError(err) != nil {
+			return false, nil
+		}
+		return false, err
+	}
+
+	// some wallets return the magic value without the abi encoding
+	return len(output) >= 4 && bytes.Equal(output[:4], EIP1271MagicValue[:]), nil
+}
+
+// VerifySignature reports whether the hash is signed by the address,
+// which is checked by EIP-1271 for the contract account, and by ecrecover for the others
+func (c *Client) VerifySignature(ctx context.Context, address common.Address, hash common.Hash, sig []byte) (bool, error) {
+	code, err := c.pool.CodeAt(ctx, address, nil)
+	if err != nil {
+		return false, errors.Wrap(err, "at ethclient.CodeAt")
+	}
+
+	if len(code) > 0 {
+		return c.IsValidSignature(ctx, address, hash, sig)
+	}
+
+	addr, err := recoverSigner(hash, sig)
+	return err == nil && addr == address, nil
+}
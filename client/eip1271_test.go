@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifySignature(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		f         = newFakeNode(1010, 10)
+		wallet, _ = GenerateAddr()
+		msg       = []byte("Hello World")
+	)
+	defer f.Close()
+
+	owner, err := NewKeySigner(TestPrivKey)
+	require.NoError(t, err)
+	other, err := NewKeySigner(TestPrivKey2)
+	require.NoError(t, err)
+
+	// the smart wallet accepts the signature of the owner
+	f.handle("eth_getCode", func(params []json.RawMessage) (interface{}, error) {
+		var addr common.Address
+		_ = json.Unmarshal(params[0], &addr)
+		if addr == wallet {
+			return hexutil.Bytes{0x01}, nil
+		}
+		return hexutil.Bytes{}, nil
+	})
+	f.handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		var call struct {
+			Data hexutil.Bytes `json:"data"`
+		}
+		_ = json.Unmarshal(params[0], &call)
+
+		args, err := isValidSignatureABI.Methods["isValidSignature"].Inputs.Unpack(call.Data[4:])
+		if err != nil {
+			return nil, err
+		}
+		signer, err := recoverSigner(args[0].([32]byte), args[1].([]byte))
+		if err != nil || signer != owner.Address() {
+			return nil, &fakeRPCError{Code: 3, Message: "execution reverted"}
+		}
+		return hexutil.Bytes(common.RightPadBytes(EIP1271MagicValue[:], 32)), nil
+	})
+
+	c, err := NewClient(ctx, f.URL, nil)
+	require.NoError(t, err)
+
+	ownerSig, err := SignMessage(owner, msg)
+	require.NoError(t, err)
+	otherSig, err := SignMessage(other, msg)
+	require.NoError(t, err)
+
+	ok, err := c.VerifySignature(ctx, wallet, MessageHash(msg), ownerSig)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = c.VerifySignature(ctx, wallet, MessageHash(msg), otherSig)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// the externally owned account
+	ok, err = c.VerifySignature(ctx, other.Address(), MessageHash(msg), otherSig)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = c.VerifySignature(ctx, other.Address(), MessageHash(msg), ownerSig)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
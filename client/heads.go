@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultHeadPollInterval = int64(1000) // 1s
+)
+
+// headWatcher follows the chain head. It subscribes newHeads when the endpoint supports
+// the subscription (ws or ipc), otherwise polls the latest header.
+// Every new head invalidates the batched receipts, refreshes the base fee cache,
+// runs the confirmer and wakes up the waiting SyncSend.
+type headWatcher struct {
+	sync.Mutex
+	head     *types.Header
+	wakeup   chan struct{}
+	arrived  chan struct{} // signals the confirmer of the new head
+	interval time.Duration
+}
+
+func newHeadWatcher(interval time.Duration) *headWatcher {
+	return &headWatcher{
+		wakeup:   make(chan struct{}),
+		arrived:  make(chan struct{}, 1),
+		interval: interval,
+	}
+}
+
+func (w *headWatcher) Head() *types.Header {
+	w.Lock()
+	defer w.Unlock()
+	return w.head
+}
+
+// next returns the channel closed on the next wake up
+func (w *headWatcher) next() <-chan struct{} {
+	w.Lock()
+	defer w.Unlock()
+	return w.wakeup
+}
+
+func (w *headWatcher) notify() {
+	w.Lock()
+	close(w.wakeup)
+	w.wakeup = make(chan struct{})
+	w.Unlock()
+}
+
+func (w *headWatcher) run(ctx context.Context, c *Client) {
+	for attempt := 1; ; attempt++ {
+		err := w.subscribe(ctx, c)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+			w.poll(ctx, c)
+			return
+		}
+
+		c.logger.Warn().Err(err).Msg("head subscription is down, resubscribing")
+		c.receipts.headsDown()
+
+		// keep following the head by polling until the resubscription
+		w.pollOnce(ctx, c)
+
+		timer := time.NewTimer(c.retryPolicy.Backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// subscribe blocks until the subscription fails or the ctx is done
+func (w *headWatcher) subscribe(ctx context.Context, c *Client) error {
+	ch := make(chan *types.Header, 16)
+
+	sub, err := c.pool.SubscribeNewHead(ctx, ch)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return err
+		case head := <-ch:
+			w.update(c, head)
+		}
+	}
+}
+
+func (w *headWatcher) poll(ctx context.Context, c *Client) {
+	timer := time.NewTicker(w.interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			w.pollOnce(ctx, c)
+		}
+	}
+}
+
+func (w *headWatcher) pollOnce(ctx context.Context, c *Client) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeoutDuration)
+	defer cancel()
+
+	head, err := c.pool.HeaderByNumber(timeoutCtx, nil)
+	if err != nil {
+		c.logger.Debug().Err(err).Msg("failed to poll head")
+		c.receipts.headsDown()
+		return
+	}
+
+	w.update(c, head)
+}
+
+func (w *headWatcher) update(c *Client, head *types.Header) {
+	w.Lock()
+	if w.head != nil && w.head.Hash() == head.Hash() {
+		w.Unlock()
+		return
+	}
+	w.head = head
+	w.Unlock()
+
+	c.receipts.onHead()
+	if head.BaseFee != nil {
+		c.baseFeeCash.update(new(big.Int).Set(head.BaseFee))
+	}
+
+	select {
+	case w.arrived <- struct{}{}:
+	default:
+	}
+
+	w.notify()
+}
+
+// confirmOnHeads checks the queued txs on every new head,
+// in addition to the polling by the workers of the confirmer
+func (w *headWatcher) confirmOnHeads(ctx context.Context, c *Client) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.arrived:
+		}
+
+		for i, n := 0, c.confirmer.QueueLen(); i < n && ctx.Err() == nil; i++ {
+			timeoutCtx, cancel := context.WithTimeout(ctx, timeoutDuration)
+			hash, err := c.confirmer.DequeueTx(timeoutCtx)
+			cancel()
+			if err != nil {
+				c.confirmer.ErrHandler(hash, err)
+			}
+		}
+	}
+}
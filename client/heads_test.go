@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tak1827/transaction-confirmer/confirm"
+)
+
+func TestHeadWatcherPolling(t *testing.T) {
+	var (
+		f      = newFakeNode(1010, 10)
+		number = int64(10)
+	)
+	defer f.Close()
+
+	f.handle("eth_getBlockByNumber", func([]json.RawMessage) (interface{}, error) {
+		return &types.Header{
+			Number:     big.NewInt(atomic.LoadInt64(&number)),
+			Difficulty: big.NewInt(0),
+			BaseFee:    big.NewInt(atomic.LoadInt64(&number)),
+		}, nil
+	})
+
+	c, err := NewClient(context.Background(), f.URL, nil, WithHeadPollInterval(10))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	next := c.heads.next()
+	go c.heads.run(ctx, &c)
+
+	select {
+	case <-next:
+	case <-time.After(time.Second):
+		require.FailNow(t, "no head")
+	}
+	require.Equal(t, uint64(10), c.heads.Head().Number.Uint64())
+	require.Equal(t, big.NewInt(10), c.baseFeeCash.base)
+	require.False(t, c.baseFeeCash.isExpired())
+	c.receipts.Lock()
+	require.True(t, c.receipts.invalidated)
+	require.True(t, c.receipts.headDriven())
+	c.receipts.Unlock()
+
+	next = c.heads.next()
+	atomic.StoreInt64(&number, 11)
+
+	select {
+	case <-next:
+	case <-time.After(time.Second):
+		require.FailNow(t, "no head")
+	}
+	require.Equal(t, uint64(11), c.heads.Head().Number.Uint64())
+}
+
+func TestHeadWatcherConfirms(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		f      = newFakeNode(1010, 10)
+		number = int64(10)
+		mined  = common.HexToHash("0x01")
+	)
+	defer f.Close()
+
+	// a new head on every poll
+	f.handle("eth_getBlockByNumber", func([]json.RawMessage) (interface{}, error) {
+		return &types.Header{
+			Number:     big.NewInt(atomic.AddInt64(&number, 1)),
+			Difficulty: big.NewInt(0),
+		}, nil
+	})
+	f.handle(MethodGetTransactionReceipt, func(params []json.RawMessage) (interface{}, error) {
+		return &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: mined, BlockNumber: big.NewInt(8), Logs: []*types.Log{}}, nil
+	})
+
+	// the workers of the confirmer hardly run
+	cfmOpts := []confirm.Opt{confirm.WithWorkerInterval(3600 * 1000), confirm.WithConfirmationInterval(0), confirm.WithConfirmationBlock(0)}
+	c, err := NewClient(ctx, f.URL, cfmOpts, WithHeadPollInterval(10))
+	require.NoError(t, err)
+	c.Start()
+	defer c.Stop()
+
+	require.NoError(t, c.EnqueueTxHash(ctx, mined.Hex()))
+	require.Eventually(t, func() bool {
+		return c.confirmer.QueueLen() == 0
+	}, time.Second, 10*time.Millisecond)
+}
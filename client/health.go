@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNodeSyncing  = errors.New("node is syncing")
+	ErrStaleHead    = errors.New("node head is stale")
+	ErrTooFewPeers  = errors.New("node has too few peers")
+	errMethodAbsent = errors.New("method is not available")
+)
+
+type NodeHealth struct {
+	Endpoint  string
+	Healthy   bool
+	Primary   bool
+	Head      uint64
+	HeadTime  time.Time
+	Syncing   bool
+	PeerCount int64 // -1 when the node does not support net_peerCount
+	Latency   time.Duration
+	Err       error // the reason of being unhealthy
+}
+
+type Health struct {
+	Healthy bool // at least one node is healthy
+	Nodes   []NodeHealth
+}
+
+// Health returns the result of the last health check of each endpoint
+func (c *Client) Health() Health {
+	return c.pool.health()
+}
+
+func (p *nodePool) health() (h Health) {
+	p.Lock()
+	primary := p.primary
+	p.Unlock()
+
+	h.Nodes = make([]NodeHealth, len(p.nodes))
+	for i, n := range p.nodes {
+		n.Lock()
+		h.Nodes[i] = NodeHealth{
+			Endpoint:  n.endpoint,
+			Healthy:   n.healthy,
+			Primary:   n == primary,
+			Head:      n.head,
+			HeadTime:  time.Unix(int64(n.headTime), 0),
+			Syncing:   n.syncing,
+			PeerCount: n.peers,
+			Latency:   n.latency,
+			Err:       n.lastErr,
+		}
+		n.Unlock()
+
+		h.Healthy = h.Healthy || h.Nodes[i].Healthy
+	}
+	return
+}
+
+func (p *nodePool) isHealthy() bool {
+	return len(p.healthyNodes()) > 0
+}
+
+// inspect checks the sync status, the staleness of the head and the peer count of the node.
+// The staleness and the peer count are checked only when WithMaxHeadAge and WithMinPeerCount are set.
+func (p *nodePool) inspect(ctx context.Context, n *node, head *types.Header) (syncing bool, peers int64, err error) {
+	peers = -1
+
+	if err = p.limiter.wait(ctx, "eth_syncing"); err != nil {
+		return
+	}
+	progress, err := n.eth.SyncProgress(ctx)
+	if err != nil {
+		err = errors.Wrap(err, "failed to get sync progress")
+		return
+	}
+	if syncing = progress != nil; syncing {
+		err = errors.Wrapf(ErrNodeSyncing, "current=%d, highest=%d", progress.CurrentBlock, progress.HighestBlock)
+		return
+	}
+
+	if age := time.Since(time.Unix(int64(head.Time), 0)); 0 < p.maxHeadAge && p.maxHeadAge < age {
+		err = errors.Wrapf(ErrStaleHead, "head(%d) is %s old", head.Number.Uint64(), age.Truncate(time.Second))
+		return
+	}
+
+	if p.minPeers <= 0 {
+		return
+	}
+
+	if err = p.limiter.wait(ctx, "net_peerCount"); err != nil {
+		return
+	}
+	if peers, err = peerCount(ctx, n.rpc); err != nil {
+		if errors.Is(err, errMethodAbsent) {
+			// hosted providers might not expose the peers
+			return false, -1, nil
+		}
+		err = errors.Wrap(err, "failed to get peer count")
+		return
+	}
+	if peers < p.minPeers {
+		err = errors.Wrapf(ErrTooFewPeers, "peers=%d, min=%d", peers, p.minPeers)
+	}
+	return
+}
+
+func peerCount(ctx context.Context, client *rpc.Client) (int64, error) {
+	var count hexutil.Uint64
+	if err := client.CallContext(ctx, &count, "net_peerCount"); err != nil {
+		var rpcErr rpc.Error
+		if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32601 {
+			return 0, errMethodAbsent
+		}
+		return 0, err
+	}
+	return int64(count), nil
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		healthy = newFakeNode(1010, 10)
+		syncing = newFakeNode(1010, 5)
+		stale   = newFakeNode(1010, 10)
+	)
+	defer healthy.Close()
+	defer syncing.Close()
+	defer stale.Close()
+
+	healthy.handle("net_peerCount", func([]json.RawMessage) (interface{}, error) {
+		return hexutil.Uint64(3), nil
+	})
+	syncing.handle("eth_syncing", func([]json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{
+			"startingBlock": hexutil.Uint64(0),
+			"currentBlock":  hexutil.Uint64(5),
+			"highestBlock":  hexutil.Uint64(10),
+		}, nil
+	})
+	stale.handle("eth_getBlockByNumber", func([]json.RawMessage) (interface{}, error) {
+		return &types.Header{
+			Number:     big.NewInt(10),
+			Difficulty: big.NewInt(0),
+			Time:       uint64(time.Now().Add(-time.Hour).Unix()),
+		}, nil
+	})
+
+	c, err := NewClient(ctx, syncing.URL, nil, WithFailoverEndpoints(stale.URL, healthy.URL), WithMaxHeadAge(60), WithMinPeerCount(1))
+	require.NoError(t, err)
+
+	h := c.Health()
+	require.True(t, h.Healthy)
+	require.Len(t, h.Nodes, 3)
+
+	require.False(t, h.Nodes[0].Healthy)
+	require.True(t, h.Nodes[0].Syncing)
+	require.ErrorIs(t, h.Nodes[0].Err, ErrNodeSyncing)
+
+	require.False(t, h.Nodes[1].Healthy)
+	require.ErrorIs(t, h.Nodes[1].Err, ErrStaleHead)
+
+	require.True(t, h.Nodes[2].Healthy)
+	require.True(t, h.Nodes[2].Primary)
+	require.Equal(t, int64(3), h.Nodes[2].PeerCount)
+}
+
+func TestSendOnUnhealthyNode(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		f     = newFakeNode(1010, 10)
+		to, _ = GenerateAddr()
+	)
+	defer f.Close()
+
+	c, err := NewClient(ctx, f.URL, nil, WithMinPeerCount(1))
+	require.NoError(t, err)
+
+	f.handle("net_peerCount", func([]json.RawMessage) (interface{}, error) {
+		return hexutil.Uint64(0), nil
+	})
+	c.pool.checkAll(ctx)
+
+	require.False(t, c.Health().Healthy)
+	require.ErrorIs(t, c.Health().Nodes[0].Err, ErrTooFewPeers)
+
+	_, err = c.AsyncSend(ctx, TestPrivKey, &to, ToWei(1.0, 9), nil, 0)
+	require.ErrorIs(t, err, ErrNoHealthyNode)
+
+	_, err = c.SyncSend(ctx, TestPrivKey, &to, ToWei(1.0, 9), nil, 0)
+	require.ErrorIs(t, err, ErrNoHealthyNode)
+}
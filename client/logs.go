@@ -0,0 +1,286 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultLogRangeLimit = uint64(2000)
+)
+
+var (
+	ErrUnknownEvent = errors.New("unknown event")
+)
+
+// LogQuery filters the logs of the events in the ABI.
+// Topics are the conditions of the indexed args following the event signature.
+type LogQuery struct {
+	ABI       *abi.ABI
+	Events    []string // all events of the ABI when empty
+	Addresses []common.Address
+	Topics    [][]common.Hash
+	FromBlock uint64
+	ToBlock   *uint64 // the latest block when nil
+}
+
+// DecodedLog has both indexed and non-indexed args in Fields
+type DecodedLog struct {
+	Event  string
+	Fields map[string]interface{}
+	Raw    types.Log
+	abi    *abi.ABI
+}
+
+// Unpack decodes the log into the struct, such as the event struct of the bindings
+func (l *DecodedLog) Unpack(out interface{}) error {
+	event := l.abi.Events[l.Event]
+	if len(l.Raw.Data) > 0 {
+		if err := l.abi.UnpackIntoInterface(out, l.Event, l.Raw.Data); err != nil {
+			return errors.Wrapf(err, "failed to unpack %s", l.Event)
+		}
+	}
+
+	if err := abi.ParseTopics(out, indexedArgs(event), l.Raw.Topics[1:]); err != nil {
+		return errors.Wrapf(err, "failed to parse topics of %s", l.Event)
+	}
+	return nil
+}
+
+func (q *LogQuery) filter() (ethereum.FilterQuery, error) {
+	var ids []common.Hash
+	if len(q.Events) == 0 {
+		for _, event := range q.ABI.Events {
+			ids = append(ids, event.ID)
+		}
+	}
+	for _, name := range q.Events {
+		event, ok := q.ABI.Events[name]
+		if !ok {
+			return ethereum.FilterQuery{}, errors.Wrapf(ErrUnknownEvent, "name=%s", name)
+		}
+		ids = append(ids, event.ID)
+	}
+
+	return ethereum.FilterQuery{
+		Addresses: q.Addresses,
+		Topics:    append([][]common.Hash{ids}, q.Topics...),
+	}, nil
+}
+
+func (q *LogQuery) decode(l types.Log) (DecodedLog, error) {
+	if len(l.Topics) == 0 {
+		return DecodedLog{}, errors.Wrap(ErrUnknownEvent, "anonymous event")
+	}
+
+	event, err := q.ABI.EventByID(l.Topics[0])
+	if err != nil {
+		return DecodedLog{}, errors.Wrapf(ErrUnknownEvent, "topic=%s", l.Topics[0])
+	}
+
+	fields := make(map[string]interface{})
+	if len(l.Data) > 0 {
+		if err = q.ABI.UnpackIntoMap(fields, event.Name, l.Data); err != nil {
+			return DecodedLog{}, errors.Wrapf(err, "failed to unpack %s", event.Name)
+		}
+	}
+
+	if err = abi.ParseTopicsIntoMap(fields, indexedArgs(*event), l.Topics[1:]); err != nil {
+		return DecodedLog{}, errors.Wrapf(err, "failed to parse topics of %s", event.Name)
+	}
+
+	return DecodedLog{Event: event.Name, Fields: fields, Raw: l, abi: q.ABI}, nil
+}
+
+// FilterLogs gets the logs by eth_getLogs split into the ranges of the log range limit.
+// The range is halved when the provider refuses the range or the number of the results.
+func (c *Client) FilterLogs(ctx context.Context, q LogQuery) ([]DecodedLog, error) {
+	query, err := q.filter()
+	if err != nil {
+		return nil, err
+	}
+
+	to := q.ToBlock
+	if to == nil {
+		latest, err := c.LatestBlockNumber(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get latest block number")
+		}
+		to = &latest
+	}
+
+	logs, err := c.filterLogs(ctx, query, q.FromBlock, *to)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded := make([]DecodedLog, len(logs))
+	for i := range logs {
+		if decoded[i], err = q.decode(logs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return decoded, nil
+}
+
+func (c *Client) filterLogs(ctx context.Context, query ethereum.FilterQuery, from, to uint64) ([]types.Log, error) {
+	var (
+		logs []types.Log
+		size = c.logRangeLimit
+	)
+
+	for start := from; start <= to; {
+		end := start + size - 1
+		if end > to || end < start {
+			end = to
+		}
+
+		query.FromBlock, query.ToBlock = new(big.Int).SetUint64(start), new(big.Int).SetUint64(end)
+		chunk, err := c.pool.FilterLogs(ctx, query)
+		if err != nil {
+			if isLogRangeExceeded(err.Error()) && size > 1 {
+				size /= 2
+				c.logger.Debug().Msgf("log range exceeded, retry by %d blocks", size)
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to filter logs of [%d, %d]", start, end)
+		}
+
+		logs = append(logs, chunk...)
+		start = end + 1
+	}
+
+	return logs, nil
+}
+
+// SubscribeLogs delivers the decoded logs of the new blocks, where the range of the query is ignored.
+// When the endpoint does not support subscription, the logs are polled by eth_getLogs until the ctx is done.
+func (c *Client) SubscribeLogs(ctx context.Context, q LogQuery, ch chan<- DecodedLog) (ethereum.Subscription, error) {
+	query, err := q.filter()
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make(chan types.Log, 128)
+	sub, err := c.pool.SubscribeFilterLogs(ctx, query, raw)
+	if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+		return c.pollLogs(ctx, q, query, ch), nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to subscribe logs")
+	}
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case <-quit:
+				return nil
+			case err := <-sub.Err():
+				return err
+			case l := <-raw:
+				decoded, err := q.decode(l)
+				if err != nil {
+					return err
+				}
+				select {
+				case ch <- decoded:
+				case <-quit:
+					return nil
+				}
+			}
+		}
+	}), nil
+}
+
+func (c *Client) pollLogs(ctx context.Context, q LogQuery, query ethereum.FilterQuery, ch chan<- DecodedLog) ethereum.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		ticker := time.NewTicker(time.Duration(c.headPollInterval) * time.Millisecond)
+		defer ticker.Stop()
+
+		var next uint64
+		for started := false; ; {
+			latest, err := c.LatestBlockNumber(ctx)
+			if err != nil {
+				c.logger.Debug().Err(err).Msg("failed to poll head")
+			} else if !started {
+				next, started = latest+1, true
+			} else if next <= latest {
+				logs, err := c.filterLogs(ctx, query, next, latest)
+				if err != nil {
+					// retried from the same block, as none of the logs is delivered
+					c.logger.Warn().Err(err).Msg("failed to poll logs")
+				} else {
+					// the undecodable log ends the subscription like the subscription of the node,
+					// so that the logs delivered before it are never redelivered
+					if done, err := deliverLogs(q, logs, ch, quit); done || err != nil {
+						return err
+					}
+					next = latest + 1
+				}
+			}
+
+			select {
+			case <-quit:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-c.heads.next():
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// deliverLogs decodes and delivers the logs in order, which is done when the quit is closed
+func deliverLogs(q LogQuery, logs []types.Log, ch chan<- DecodedLog, quit <-chan struct{}) (done bool, err error) {
+	for i := range logs {
+		decoded, err := q.decode(logs[i])
+		if err != nil {
+			return false, err
+		}
+		select {
+		case ch <- decoded:
+		case <-quit:
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func indexedArgs(event abi.Event) abi.Arguments {
+	var indexed abi.Arguments
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	return indexed
+}
+
+func isLogRangeExceeded(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, s := range []string{
+		"query returned more than", // infura, geth
+		"block range",              // alchemy, quicknode and the others
+		"range too large",
+		"range is too large",
+		"exceed maximum block range",
+		"log response size exceeded",
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
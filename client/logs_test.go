@@ -0,0 +1,205 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+const testTransferABI = `[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"}]`
+
+type testTransfer struct {
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+}
+
+// handleLogs responds a Transfer log per block, and refuses the range wider than maxRange
+func handleLogs(t *testing.T, f *fakeNode, parsed abi.ABI, maxRange uint64) *[][2]uint64 {
+	var (
+		mu     sync.Mutex
+		ranges [][2]uint64
+	)
+	f.handle("eth_getLogs", func(params []json.RawMessage) (interface{}, error) {
+		var q struct {
+			FromBlock hexutil.Uint64 `json:"fromBlock"`
+			ToBlock   hexutil.Uint64 `json:"toBlock"`
+		}
+		require.NoError(t, json.Unmarshal(params[0], &q))
+		if uint64(q.ToBlock-q.FromBlock)+1 > maxRange {
+			return nil, &fakeRPCError{Code: -32005, Message: "query returned more than 10000 results"}
+		}
+
+		mu.Lock()
+		ranges = append(ranges, [2]uint64{uint64(q.FromBlock), uint64(q.ToBlock)})
+		mu.Unlock()
+
+		var logs []*types.Log
+		for n := uint64(q.FromBlock); n <= uint64(q.ToBlock); n++ {
+			data, err := parsed.Events["Transfer"].Inputs.NonIndexed().Pack(new(big.Int).SetUint64(n))
+			require.NoError(t, err)
+			logs = append(logs, &types.Log{
+				Address:     common.HexToAddress("0x01"),
+				Topics:      []common.Hash{parsed.Events["Transfer"].ID, common.HexToAddress(TestAccount).Hash(), common.HexToAddress(TestAccount2).Hash()},
+				Data:        data,
+				BlockNumber: n,
+			})
+		}
+		return logs, nil
+	})
+	return &ranges
+}
+
+func TestFilterLogs(t *testing.T) {
+	var (
+		ctx = context.Background()
+		f   = newFakeNode(1010, 10)
+	)
+	defer f.Close()
+
+	parsed, err := abi.JSON(strings.NewReader(testTransferABI))
+	require.NoError(t, err)
+	ranges := handleLogs(t, f, parsed, 4)
+
+	c, err := NewClient(ctx, f.URL, nil, WithLogRangeLimit(8))
+	require.NoError(t, err)
+
+	to := uint64(9)
+	logs, err := c.FilterLogs(ctx, LogQuery{ABI: &parsed, Events: []string{"Transfer"}, FromBlock: 1, ToBlock: &to})
+	require.NoError(t, err)
+	require.Len(t, logs, 9)
+
+	// halved from 8 to 4 blocks
+	require.Equal(t, [][2]uint64{{1, 4}, {5, 8}, {9, 9}}, *ranges)
+
+	require.Equal(t, "Transfer", logs[0].Event)
+	require.Equal(t, common.HexToAddress(TestAccount), logs[0].Fields["from"])
+	require.Equal(t, big.NewInt(1), logs[0].Fields["value"])
+
+	var transfer testTransfer
+	require.NoError(t, logs[8].Unpack(&transfer))
+	require.Equal(t, common.HexToAddress(TestAccount2), transfer.To)
+	require.Equal(t, big.NewInt(9), transfer.Value)
+
+	_, err = c.FilterLogs(ctx, LogQuery{ABI: &parsed, Events: []string{"Approval"}})
+	require.ErrorIs(t, err, ErrUnknownEvent)
+}
+
+func TestSubscribeLogsPolling(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		f    = newFakeNode(1010, 10)
+		head = uint64(10)
+		mu   sync.Mutex
+	)
+	defer f.Close()
+
+	f.handle("eth_getBlockByNumber", func([]json.RawMessage) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return &types.Header{Number: new(big.Int).SetUint64(head), Difficulty: big.NewInt(0), Time: uint64(time.Now().Unix())}, nil
+	})
+
+	parsed, err := abi.JSON(strings.NewReader(testTransferABI))
+	require.NoError(t, err)
+	handleLogs(t, f, parsed, 100)
+
+	c, err := NewClient(ctx, f.URL, nil, WithHeadPollInterval(10))
+	require.NoError(t, err)
+
+	ch := make(chan DecodedLog)
+	sub, err := c.SubscribeLogs(ctx, LogQuery{ABI: &parsed}, ch)
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	// let the polling start from the block 11
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	head = 12
+	mu.Unlock()
+
+	for _, n := range []uint64{11, 12} {
+		select {
+		case l := <-ch:
+			require.Equal(t, n, l.Raw.BlockNumber)
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+}
+
+func TestSubscribeLogsPollingUndecodable(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		f    = newFakeNode(1010, 10)
+		head = uint64(10)
+		mu   sync.Mutex
+	)
+	defer f.Close()
+
+	f.handle("eth_getBlockByNumber", func([]json.RawMessage) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return &types.Header{Number: new(big.Int).SetUint64(head), Difficulty: big.NewInt(0), Time: uint64(time.Now().Unix())}, nil
+	})
+
+	parsed, err := abi.JSON(strings.NewReader(testTransferABI))
+	require.NoError(t, err)
+	handleLogs(t, f, parsed, 100)
+
+	// the log of the block 12 is not in the abi
+	handler := f.handlers["eth_getLogs"]
+	f.handle("eth_getLogs", func(params []json.RawMessage) (interface{}, error) {
+		res, err := handler(params)
+		if err != nil {
+			return nil, err
+		}
+		logs := res.([]*types.Log)
+		for _, l := range logs {
+			if l.BlockNumber == 12 {
+				l.Topics[0] = common.HexToHash("0x01")
+			}
+		}
+		return logs, nil
+	})
+
+	c, err := NewClient(ctx, f.URL, nil, WithHeadPollInterval(10))
+	require.NoError(t, err)
+
+	ch := make(chan DecodedLog)
+	sub, err := c.SubscribeLogs(ctx, LogQuery{ABI: &parsed}, ch)
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	head = 13
+	mu.Unlock()
+
+	select {
+	case l := <-ch:
+		require.Equal(t, uint64(11), l.Raw.BlockNumber)
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	// the subscription ends without redelivering the block 11
+	select {
+	case err := <-sub.Err():
+		require.ErrorIs(t, err, ErrUnknownEvent)
+	case l := <-ch:
+		t.Fatalf("unexpected log of the block %d", l.Raw.BlockNumber)
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+}
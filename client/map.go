@@ -27,3 +27,13 @@ func (m *safeMap) has(hash string) bool {
 	m.Unlock()
 	return exist
 }
+
+func (m *safeMap) keys() []string {
+	m.Lock()
+	keys := make([]string, 0, len(m.item))
+	for k := range m.item {
+		keys = append(keys, k)
+	}
+	m.Unlock()
+	return keys
+}
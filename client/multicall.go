@@ -0,0 +1,215 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultMulticallChunkSize = 100
+	// the parallelism of the QueryContract fallback
+	multicallFallbackWorkers = 8
+
+	multicall3ABI = `[{"inputs":[{"components":[{"internalType":"address","name":"target","type":"address"},{"internalType":"bool","name":"allowFailure","type":"bool"},{"internalType":"bytes","name":"callData","type":"bytes"}],"internalType":"struct Multicall3.Call3[]","name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"bytes","name":"returnData","type":"bytes"}],"internalType":"struct Multicall3.Result[]","name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]`
+)
+
+var (
+	// DefaultMulticallAddress is the address of Multicall3, which is the same on most chains
+	DefaultMulticallAddress = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+	ErrCallFailed = errors.New("call failed")
+
+	multicallABI abi.ABI
+)
+
+func init() {
+	var err error
+	if multicallABI, err = abi.JSON(strings.NewReader(multicall3ABI)); err != nil {
+		panic(err)
+	}
+}
+
+type MulticallCall struct {
+	To    common.Address
+	Input []byte
+	// AllowFailure lets the other calls succeed even if this call fails,
+	// otherwise the failure of this call fails the whole Multicall
+	AllowFailure bool
+}
+
+// MulticallResult is the result of each call. Err wraps ErrCallFailed when the call reverted in Multicall3,
+// and Output is the revert data in that case.
+// Like QueryContract, Err wraps bind.ErrNoCode when the output is empty and the address has no code.
+type MulticallResult struct {
+	Output []byte
+	Err    error
+}
+
+type call3 struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type result3 struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// multicaller remembers whether Multicall3 is deployed on the chain
+type multicaller struct {
+	sync.Mutex
+	address   common.Address
+	chunkSize int
+	checked   bool
+	deployed  bool
+}
+
+func (m *multicaller) isDeployed(ctx context.Context, c *Client) (bool, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.checked {
+		return m.deployed, nil
+	}
+
+	code, err := c.pool.CodeAt(ctx, m.address, nil)
+	if err != nil {
+		return false, errors.Wrap(err, "at ethclient.CodeAt")
+	}
+
+	m.checked, m.deployed = true, len(code) > 0
+	if !m.deployed {
+		c.logger.Info().Msgf("multicall(=%s) is not deployed, fallback to parallel calls", m.address)
+	}
+	return m.deployed, nil
+}
+
+// Multicall aggregates the calls through Multicall3 in one eth_call per chunk.
+// When Multicall3 is not deployed, the calls are sent in parallel by QueryContract.
+// The results are in the same order as the calls.
+func (c *Client) Multicall(ctx context.Context, calls []MulticallCall) ([]MulticallResult, error) {
+	deployed, err := c.multicall.isDeployed(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if !deployed {
+		return c.multicallFallback(ctx, calls)
+	}
+
+	results := make([]MulticallResult, 0, len(calls))
+	for start := 0; start < len(calls); start += c.multicall.chunkSize {
+		end := start + c.multicall.chunkSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+
+		chunk, err := c.aggregate3(ctx, calls[start:end])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to aggregate calls[%d:%d]", start, end)
+		}
+		for i := range chunk {
+			results = append(results, MulticallResult{Output: chunk[i].ReturnData})
+			if !chunk[i].Success {
+				results[start+i].Err = errors.Wrapf(ErrCallFailed, "call to %s", calls[start+i].To)
+			}
+		}
+	}
+
+	if err := c.checkNoCode(ctx, calls, results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// checkNoCode fails the empty outputs of the addresses without code, which succeed in Multicall3
+// but fail in QueryContract, so that the results are the same regardless of the deployment of Multicall3
+func (c *Client) checkNoCode(ctx context.Context, calls []MulticallCall, results []MulticallResult) error {
+	codes := make(map[common.Address]bool)
+	for i := range results {
+		if results[i].Err != nil || len(results[i].Output) > 0 {
+			continue
+		}
+
+		hasCode, ok := codes[calls[i].To]
+		if !ok {
+			code, err := c.pool.CodeAt(ctx, calls[i].To, nil)
+			if err != nil {
+				return errors.Wrap(err, "at ethclient.CodeAt")
+			}
+			hasCode = len(code) > 0
+			codes[calls[i].To] = hasCode
+		}
+		if hasCode {
+			continue
+		}
+
+		results[i].Err = errors.Wrapf(bind.ErrNoCode, "call to %s", calls[i].To)
+		if !calls[i].AllowFailure {
+			return errors.Wrapf(results[i].Err, "failed calls[%d]", i)
+		}
+	}
+	return nil
+}
+
+func (c *Client) aggregate3(ctx context.Context, calls []MulticallCall) ([]result3, error) {
+	args := make([]call3, len(calls))
+	for i := range calls {
+		args[i] = call3{Target: calls[i].To, AllowFailure: calls[i].AllowFailure, CallData: calls[i].Input}
+	}
+
+	input, err := multicallABI.Pack("aggregate3", args)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pack aggregate3")
+	}
+
+	output, err := c.pool.CallContract(ctx, ethereum.CallMsg{To: &c.multicall.address, Data: input}, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to call multicall")
+	}
+
+	unpacked, err := multicallABI.Unpack("aggregate3", output)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unpack aggregate3")
+	}
+
+	results := *abi.ConvertType(unpacked[0], new([]result3)).(*[]result3)
+	if len(results) != len(calls) {
+		return nil, errors.Errorf("unexpected length of results, want=%d, got=%d", len(calls), len(results))
+	}
+	return results, nil
+}
+
+func (c *Client) multicallFallback(ctx context.Context, calls []MulticallCall) ([]MulticallResult, error) {
+	var (
+		results = make([]MulticallResult, len(calls))
+		sem     = make(chan struct{}, multicallFallbackWorkers)
+		wg      sync.WaitGroup
+	)
+
+	for i := range calls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() { <-sem; wg.Done() }()
+			results[i].Output, results[i].Err = c.QueryContract(ctx, calls[i].To, calls[i].Input)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range calls {
+		if results[i].Err != nil && !calls[i].AllowFailure {
+			return nil, errors.Wrapf(results[i].Err, "failed calls[%d]", i)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+// noCodeAccount is the address without code
+var noCodeAccount = common.HexToAddress(TestAccount2)
+
+// handleEcho responds the input as the output, and reverts when the input is 0xff
+func handleEcho(f *fakeNode, deployed bool) {
+	f.handle("eth_getCode", func(params []json.RawMessage) (interface{}, error) {
+		var addr common.Address
+		_ = json.Unmarshal(params[0], &addr)
+		if addr == noCodeAccount || (addr == DefaultMulticallAddress && !deployed) {
+			return hexutil.Bytes{}, nil
+		}
+		return hexutil.Bytes{0x01}, nil
+	})
+	f.handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		var msg struct {
+			To   common.Address `json:"to"`
+			Data hexutil.Bytes  `json:"data"`
+		}
+		_ = json.Unmarshal(params[0], &msg)
+
+		if msg.To != DefaultMulticallAddress {
+			if bytes.Equal(msg.Data, []byte{0xff}) {
+				return nil, &fakeRPCError{Code: 3, Message: "execution reverted"}
+			}
+			return msg.Data, nil
+		}
+
+		args, err := multicallABI.Methods["aggregate3"].Inputs.Unpack(msg.Data[4:])
+		if err != nil {
+			return nil, err
+		}
+		calls := *abi.ConvertType(args[0], new([]call3)).(*[]call3)
+		results := make([]result3, len(calls))
+		for i := range calls {
+			results[i].Success = !bytes.Equal(calls[i].CallData, []byte{0xff})
+			results[i].ReturnData = calls[i].CallData
+			if !results[i].Success && !calls[i].AllowFailure {
+				return nil, &fakeRPCError{Code: 3, Message: "execution reverted"}
+			}
+		}
+		output, err := multicallABI.Methods["aggregate3"].Outputs.Pack(results)
+		return hexutil.Bytes(output), err
+	})
+}
+
+// requireNoCode checks the empty output is an error only for the address without code
+func requireNoCode(t *testing.T, c *Client) {
+	ctx := context.Background()
+	calls := []MulticallCall{
+		{To: common.HexToAddress(TestAccount)},
+		{To: noCodeAccount, AllowFailure: true},
+	}
+
+	results, err := c.Multicall(ctx, calls)
+	require.NoError(t, err)
+	require.NoError(t, results[0].Err)
+	require.Empty(t, results[0].Output)
+	require.ErrorIs(t, results[1].Err, bind.ErrNoCode)
+
+	calls[1].AllowFailure = false
+	_, err = c.Multicall(ctx, calls)
+	require.ErrorIs(t, err, bind.ErrNoCode)
+}
+
+func TestMulticall(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		f      = newFakeNode(1010, 10)
+		target = common.HexToAddress(TestAccount)
+		calls  = []MulticallCall{
+			{To: target, Input: []byte{0x01}},
+			{To: target, Input: []byte{0xff}, AllowFailure: true},
+			{To: target, Input: []byte{0x03}},
+		}
+	)
+	defer f.Close()
+	handleEcho(f, true)
+
+	c, err := NewClient(ctx, f.URL, nil, WithMulticallChunkSize(2))
+	require.NoError(t, err)
+
+	results, err := c.Multicall(ctx, calls)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.Equal(t, []byte{0x01}, results[0].Output)
+	require.NoError(t, results[0].Err)
+	require.ErrorIs(t, results[1].Err, ErrCallFailed)
+	require.Equal(t, []byte{0x03}, results[2].Output)
+	require.NoError(t, results[2].Err)
+
+	// chunked into 2 calls, and the code is checked only once
+	require.Equal(t, 2, f.called("eth_call"))
+	require.Equal(t, 1, f.called("eth_getCode"))
+
+	calls[1].AllowFailure = false
+	_, err = c.Multicall(ctx, calls)
+	require.Error(t, err)
+
+	requireNoCode(t, &c)
+}
+
+func TestMulticallFallback(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		f      = newFakeNode(1010, 10)
+		target = common.HexToAddress(TestAccount)
+		calls  = []MulticallCall{
+			{To: target, Input: []byte{0x01}},
+			{To: target, Input: []byte{0xff}, AllowFailure: true},
+			{To: target, Input: []byte{0x03}},
+		}
+	)
+	defer f.Close()
+	handleEcho(f, false)
+
+	c, err := NewClient(ctx, f.URL, nil)
+	require.NoError(t, err)
+
+	results, err := c.Multicall(ctx, calls)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x01}, results[0].Output)
+	require.Error(t, results[1].Err)
+	require.Equal(t, []byte{0x03}, results[2].Output)
+	require.Equal(t, 3, f.called("eth_call"))
+
+	calls[1].AllowFailure = false
+	_, err = c.Multicall(ctx, calls)
+	require.Error(t, err)
+
+	requireNoCode(t, &c)
+}
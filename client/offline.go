@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrUnsignedTx = errors.New("tx is not signed")
+)
+
+// addressNoncer gets the nonce by the address, instead of the private key
+type addressNoncer struct {
+	client *Client
+}
+
+func (n addressNoncer) Nonce(ctx context.Context, key string) (uint64, error) {
+	if !common.IsHexAddress(key) {
+		return 0, errors.Errorf("invalid address(=%s)", key)
+	}
+	return n.client.pool.NonceAt(ctx, common.HexToAddress(key), nil)
+}
+
+// BuildTx builds the unsigned tx of the from, with the nonce and the fees resolved.
+// The nonce is assigned from the nonce cache shared with SyncSend and AsyncSend,
+// so the tx should be broadcasted by Broadcast to give back the nonce on failure.
+func (c *Client) BuildTx(ctx context.Context, from common.Address, to *common.Address, amount *big.Int, input []byte, gasLimit uint64) (*types.Transaction, error) {
+	n, err := c.nonceCash.Issue(ctx, from, c)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get nonce")
+	}
+
+	txdata, err := c.buildTxData(ctx, from, n, to, amount, input, gasLimit)
+	if err != nil {
+		c.nonceCash.release(from, n)
+		return nil, err
+	}
+
+	return types.NewTx(txdata), nil
+}
+
+// SignOffline signs the tx without rpc, such as on the air-gapped machine
+func SignOffline(tx *types.Transaction, chainID *big.Int, priv string) (*types.Transaction, error) {
+	privKey, err := crypto.HexToECDSA(priv)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse private key")
+	}
+
+	signedTx, err := types.SignTx(tx, types.NewLondonSigner(chainID), privKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "at types.SignTx")
+	}
+	return signedTx, nil
+}
+
+// EncodeRawTx encodes the tx to the hex of the binary, which is accepted by eth_sendRawTransaction when signed
+func EncodeRawTx(tx *types.Transaction) (string, error) {
+	b, err := tx.MarshalBinary()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode tx")
+	}
+	return hexutil.Encode(b), nil
+}
+
+func DecodeRawTx(raw string) (*types.Transaction, error) {
+	b, err := hexutil.Decode(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode hex")
+	}
+
+	tx := new(types.Transaction)
+	if err = tx.UnmarshalBinary(b); err != nil {
+		return nil, errors.Wrap(err, "failed to decode tx")
+	}
+	return tx, nil
+}
+
+func EncodeTxJSON(tx *types.Transaction) ([]byte, error) {
+	b, err := json.Marshal(tx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode tx")
+	}
+	return b, nil
+}
+
+func DecodeTxJSON(b []byte) (*types.Transaction, error) {
+	tx := new(types.Transaction)
+	if err := json.Unmarshal(b, tx); err != nil {
+		return nil, errors.Wrap(err, "failed to decode tx")
+	}
+	return tx, nil
+}
+
+// Broadcast sends the signed raw tx, and waits for the confirmation like SyncSend.
+// The nonce issued by BuildTx is given back to the nonce cache on failure, unless the nonce is already used.
+func (c *Client) Broadcast(ctx context.Context, rawTx string) (string, error) {
+	return c.SyncSendRaw(ctx, rawTx)
+}
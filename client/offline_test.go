@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tak1827/transaction-confirmer/confirm"
+)
+
+func TestOfflineSign(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		f      = newFakeNode(1010, 10)
+		from   = common.HexToAddress(TestAccount)
+		to, _  = GenerateAddr()
+		sent   *types.Transaction
+		reject string
+	)
+	defer f.Close()
+
+	f.handle("eth_getTransactionCount", func([]json.RawMessage) (interface{}, error) {
+		return hexutil.Uint64(3), nil
+	})
+	f.handle(MethodSendRawTransaction, func(params []json.RawMessage) (interface{}, error) {
+		if reject != "" {
+			return nil, &fakeRPCError{Code: -32000, Message: reject}
+		}
+		var raw hexutil.Bytes
+		_ = json.Unmarshal(params[0], &raw)
+		sent = new(types.Transaction)
+		if err := sent.UnmarshalBinary(raw); err != nil {
+			return nil, err
+		}
+		return sent.Hash(), nil
+	})
+	f.handle(MethodGetTransactionReceipt, func(params []json.RawMessage) (interface{}, error) {
+		var hash common.Hash
+		_ = json.Unmarshal(params[0], &hash)
+		return &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: hash, BlockNumber: big.NewInt(8), Logs: []*types.Log{}}, nil
+	})
+
+	c, err := NewClient(ctx, f.URL, []confirm.Opt{confirm.WithWorkerInterval(10)}, WithSyncSendConfirmInterval(10))
+	require.NoError(t, err)
+	c.Start()
+	defer c.Stop()
+
+	tx, err := c.BuildTx(ctx, from, &to, big.NewInt(1), nil, 21000)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), tx.Nonce())
+
+	// the unsigned tx is carried to the air-gapped machine
+	b, err := EncodeTxJSON(tx)
+	require.NoError(t, err)
+	unsigned, err := DecodeTxJSON(b)
+	require.NoError(t, err)
+	require.Equal(t, tx.Hash(), unsigned.Hash())
+
+	signed, err := SignOffline(unsigned, c.ChainID(), TestPrivKey)
+	require.NoError(t, err)
+
+	raw, err := EncodeRawTx(signed)
+	require.NoError(t, err)
+	decoded, err := DecodeRawTx(raw)
+	require.NoError(t, err)
+	require.Equal(t, signed.Hash(), decoded.Hash())
+
+	unsignedRaw, err := EncodeRawTx(tx)
+	require.NoError(t, err)
+	_, err = c.Broadcast(ctx, unsignedRaw)
+	require.ErrorIs(t, err, ErrUnsignedTx)
+
+	hash, err := c.Broadcast(ctx, raw)
+	require.NoError(t, err)
+	require.Equal(t, signed.Hash().Hex(), hash)
+	require.Equal(t, signed.Hash(), sent.Hash())
+
+	// the next tx gets the next nonce
+	tx, err = c.BuildTx(ctx, from, &to, big.NewInt(1), nil, 21000)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), tx.Nonce())
+
+	// the online send of the same account shares the nonce
+	_, err = c.AsyncSend(ctx, TestPrivKey, &to, big.NewInt(1), nil, 21000)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), sent.Nonce())
+
+	build := func() (*types.Transaction, string) {
+		tx, err := c.BuildTx(ctx, from, &to, big.NewInt(1), nil, 21000)
+		require.NoError(t, err)
+		signed, err := SignOffline(tx, c.ChainID(), TestPrivKey)
+		require.NoError(t, err)
+		raw, err := EncodeRawTx(signed)
+		require.NoError(t, err)
+		return tx, raw
+	}
+
+	// the rebroadcast rejected by the used nonce does not give back the nonce
+	reject = "nonce too low"
+	_, err = c.Broadcast(ctx, raw)
+	require.Error(t, err)
+
+	// the built tx rejected by the other reason gives back the nonce
+	reject = "replacement transaction underpriced"
+	tx, raw = build()
+	require.Equal(t, uint64(6), tx.Nonce())
+	_, err = c.Broadcast(ctx, raw)
+	require.Error(t, err)
+
+	// the already known tx is sent, so the nonce is used
+	reject = "already known"
+	tx, raw = build()
+	require.Equal(t, uint64(6), tx.Nonce())
+	_, err = c.Broadcast(ctx, raw)
+	require.NoError(t, err)
+
+	reject = ""
+	tx, _ = build()
+	require.Equal(t, uint64(7), tx.Nonce())
+}
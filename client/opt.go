@@ -4,6 +4,7 @@ import (
 	"math/big"
 	"os"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/rs/zerolog"
 )
 
@@ -98,3 +99,238 @@ func (o BaseFeeCashTTLOpt) Apply(c *Client) {
 func WithBaseFeeCashTTL(ttl int64) BaseFeeCashTTLOpt {
 	return BaseFeeCashTTLOpt(ttl)
 }
+
+type SignedNonceTTLOpt int64
+
+func (o SignedNonceTTLOpt) Apply(c *Client) {
+	c.signedNonceTTL = int64(o)
+}
+
+// WithSignedNonceTTL sets the seconds the nonce of the tx signed by TransactOpts is kept unsent, such as by opts.NoSend.
+// After the ttl, the nonce is given back and assigned to the next tx, so broadcasting the expired tx later
+// conflicts with it, and one of them is dropped. The signed txs never expire when 0.
+func WithSignedNonceTTL(ttl int64) SignedNonceTTLOpt {
+	return SignedNonceTTLOpt(ttl)
+}
+
+type FailoverEndpointsOpt []string
+
+func (o FailoverEndpointsOpt) Apply(c *Client) {
+	c.endpoints = append(c.endpoints, o...)
+}
+func WithFailoverEndpoints(endpoints ...string) FailoverEndpointsOpt {
+	return FailoverEndpointsOpt(endpoints)
+}
+
+type HealthCheckIntervalOpt int64
+
+func (o HealthCheckIntervalOpt) Apply(c *Client) {
+	c.healthCheckInterval = int64(o)
+}
+func WithHealthCheckInterval(interval int64) HealthCheckIntervalOpt {
+	if interval <= 0 {
+		panic("HealthCheckInterval should be positive")
+	}
+	return HealthCheckIntervalOpt(interval)
+}
+
+type MaxHeadLagOpt uint64
+
+func (o MaxHeadLagOpt) Apply(c *Client) {
+	c.maxHeadLag = uint64(o)
+}
+func WithMaxHeadLag(blocks uint64) MaxHeadLagOpt {
+	return MaxHeadLagOpt(blocks)
+}
+
+type RetryPolicyOpt RetryPolicy
+
+func (o RetryPolicyOpt) Apply(c *Client) {
+	c.retryPolicy = RetryPolicy(o)
+}
+func WithRetryPolicy(policy RetryPolicy) RetryPolicyOpt {
+	if policy.MaxAttempts <= 0 {
+		panic("MaxAttempts should be positive")
+	}
+	return RetryPolicyOpt(policy)
+}
+
+type MethodRetryPolicyOpt struct {
+	method string
+	policy RetryPolicy
+}
+
+func (o MethodRetryPolicyOpt) Apply(c *Client) {
+	c.retryPolicies[o.method] = o.policy
+}
+
+// WithMethodRetryPolicy overrides the retry policy of the json-rpc method, such as eth_sendRawTransaction
+func WithMethodRetryPolicy(method string, policy RetryPolicy) MethodRetryPolicyOpt {
+	if policy.MaxAttempts <= 0 {
+		panic("MaxAttempts should be positive")
+	}
+	return MethodRetryPolicyOpt{method, policy}
+}
+
+type ReadRateLimitOpt struct {
+	rate  float64
+	burst int
+}
+
+func (o ReadRateLimitOpt) Apply(c *Client) {
+	c.readRateLimit = o.rate
+	c.readBurst = o.burst
+}
+
+// WithReadRateLimit limits the read calls to rate per second, allowing the burst
+func WithReadRateLimit(rate float64, burst int) ReadRateLimitOpt {
+	if rate <= 0 {
+		panic("ReadRateLimit should be positive")
+	}
+	return ReadRateLimitOpt{rate, burst}
+}
+
+type WriteRateLimitOpt struct {
+	rate  float64
+	burst int
+}
+
+func (o WriteRateLimitOpt) Apply(c *Client) {
+	c.writeRateLimit = o.rate
+	c.writeBurst = o.burst
+}
+
+// WithWriteRateLimit limits the eth_sendRawTransaction calls to rate per second, allowing the burst
+func WithWriteRateLimit(rate float64, burst int) WriteRateLimitOpt {
+	if rate <= 0 {
+		panic("WriteRateLimit should be positive")
+	}
+	return WriteRateLimitOpt{rate, burst}
+}
+
+type BatchSizeOpt int
+
+func (o BatchSizeOpt) Apply(c *Client) {
+	c.batchSize = int(o)
+}
+func WithBatchSize(size int) BatchSizeOpt {
+	if size <= 0 {
+		panic("BatchSize should be positive")
+	}
+	return BatchSizeOpt(size)
+}
+
+type ConfirmBatchTTLOpt int64
+
+func (o ConfirmBatchTTLOpt) Apply(c *Client) {
+	c.confirmBatchTTL = int64(o)
+}
+
+// WithConfirmBatchTTL sets how long the batched receipts are reused by ConfirmTx in milliseconds
+func WithConfirmBatchTTL(ttl int64) ConfirmBatchTTLOpt {
+	return ConfirmBatchTTLOpt(ttl)
+}
+
+type HeadPollIntervalOpt int64
+
+func (o HeadPollIntervalOpt) Apply(c *Client) {
+	c.headPollInterval = int64(o)
+}
+
+// WithHeadPollInterval sets the interval to poll the head in milliseconds, when the endpoint does not support subscription
+func WithHeadPollInterval(interval int64) HeadPollIntervalOpt {
+	if interval <= 0 {
+		panic("HeadPollInterval should be positive")
+	}
+	return HeadPollIntervalOpt(interval)
+}
+
+type MaxHeadAgeOpt int64
+
+func (o MaxHeadAgeOpt) Apply(c *Client) {
+	c.maxHeadAge = int64(o)
+}
+
+// WithMaxHeadAge marks the node unhealthy when the head is older than age seconds.
+// The staleness is not checked without this option, as the chain mining on demand, such as the dev chain, has the old head.
+// Set the age longer than the block interval of the chain, e.g. 60 for the mainnet.
+func WithMaxHeadAge(age int64) MaxHeadAgeOpt {
+	if age <= 0 {
+		panic("MaxHeadAge should be positive")
+	}
+	return MaxHeadAgeOpt(age)
+}
+
+type MinPeerCountOpt int64
+
+func (o MinPeerCountOpt) Apply(c *Client) {
+	c.minPeerCount = int64(o)
+}
+
+// WithMinPeerCount marks the node unhealthy when the node has less peers than count.
+// The peer count is not checked without this option, as the single node of the private chain has no peer.
+// The node not exposing net_peerCount, such as the hosted provider, is not checked either.
+func WithMinPeerCount(count int64) MinPeerCountOpt {
+	return MinPeerCountOpt(count)
+}
+
+type MulticallAddressOpt common.Address
+
+func (o MulticallAddressOpt) Apply(c *Client) {
+	c.multicallAddress = common.Address(o)
+}
+
+// WithMulticallAddress sets the address of Multicall3, when it is deployed on the other address than the default
+func WithMulticallAddress(address common.Address) MulticallAddressOpt {
+	return MulticallAddressOpt(address)
+}
+
+type MulticallChunkSizeOpt int
+
+func (o MulticallChunkSizeOpt) Apply(c *Client) {
+	c.multicallChunkSize = int(o)
+}
+
+// WithMulticallChunkSize sets the max number of the calls aggregated in one eth_call
+func WithMulticallChunkSize(size int) MulticallChunkSizeOpt {
+	if size <= 0 {
+		panic("MulticallChunkSize should be positive")
+	}
+	return MulticallChunkSizeOpt(size)
+}
+
+type PreflightOpt bool
+
+func (o PreflightOpt) Apply(c *Client) {
+	c.preflight = bool(o)
+}
+
+// WithPreflight simulates the tx at the pending block before broadcasting, and refuses to send the tx which will revert
+func WithPreflight(enabled bool) PreflightOpt {
+	return PreflightOpt(enabled)
+}
+
+type Create2DeployerOpt common.Address
+
+func (o Create2DeployerOpt) Apply(c *Client) {
+	c.create2Deployer = common.Address(o)
+}
+
+// WithCreate2Deployer sets the address of the deterministic deployment proxy used by DeployCreate2
+func WithCreate2Deployer(address common.Address) Create2DeployerOpt {
+	return Create2DeployerOpt(address)
+}
+
+type LogRangeLimitOpt uint64
+
+func (o LogRangeLimitOpt) Apply(c *Client) {
+	c.logRangeLimit = uint64(o)
+}
+
+// WithLogRangeLimit sets the max number of blocks queried by one eth_getLogs
+func WithLogRangeLimit(limit uint64) LogRangeLimitOpt {
+	if limit == 0 {
+		panic("LogRangeLimit should be positive")
+	}
+	return LogRangeLimitOpt(limit)
+}
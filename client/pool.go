@@ -0,0 +1,611 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	DefaultHealthCheckInterval = int64(5000) // 5s
+	DefaultMaxHeadLag          = uint64(3)   // 3 blocks
+)
+
+var (
+	ErrNoHealthyNode   = errors.New("no healthy node")
+	ErrChainIDMismatch = errors.New("chain id mismatch")
+
+	// ErrNodeUnhealthy is the alias of ErrNoHealthyNode
+	ErrNodeUnhealthy = ErrNoHealthyNode
+)
+
+type node struct {
+	sync.Mutex
+	endpoint string
+	rpc      *rpc.Client
+	eth      *ethclient.Client
+
+	verified bool // chain id is checked
+	healthy  bool
+	head     uint64
+	headTime uint64
+	syncing  bool
+	peers    int64 // -1 when the node does not tell
+	latency  time.Duration
+	lastErr  error
+}
+
+func (n *node) dial(ctx context.Context) error {
+	n.Lock()
+	defer n.Unlock()
+
+	if n.rpc != nil {
+		return nil
+	}
+
+	rpcclient, err := rpc.DialContext(ctx, n.endpoint)
+	if err != nil {
+		return errors.Wrapf(err, "failed to conecting endpoint(%s)", n.endpoint)
+	}
+
+	n.rpc = rpcclient
+	n.eth = ethclient.NewClient(rpcclient)
+	return nil
+}
+
+func (n *node) close() {
+	n.Lock()
+	defer n.Unlock()
+
+	if n.rpc != nil {
+		n.rpc.Close()
+	}
+}
+
+type poolConfig struct {
+	maxLag     uint64
+	maxHeadAge time.Duration // 0 disables the staleness check
+	minPeers   int64
+	logger     zerolog.Logger
+}
+
+// nodePool routes every rpc call of the client to the configured endpoints.
+// Reads go to the primary node, and fail over to the other healthy nodes when the primary
+// can not be reached. Transactions are broadcasted to all healthy nodes.
+type nodePool struct {
+	sync.Mutex
+	poolConfig
+	nodes   []*node
+	primary *node
+	chainID *big.Int
+
+	retryPolicy   RetryPolicy
+	retryPolicies map[string]RetryPolicy // by json-rpc method
+	limiter       *rateLimiter
+}
+
+func newNodePool(ctx context.Context, endpoints []string, cfg poolConfig) (*nodePool, error) {
+	p := &nodePool{
+		poolConfig:    cfg,
+		nodes:         make([]*node, len(endpoints)),
+		retryPolicy:   DefaultRetryPolicy,
+		retryPolicies: map[string]RetryPolicy{MethodSendRawTransaction: DefaultSendRetryPolicy},
+		limiter:       newRateLimiter(0, 0, 0, 0),
+	}
+
+	for i := range endpoints {
+		p.nodes[i] = &node{endpoint: endpoints[i]}
+	}
+
+	// check in order, so that the chain id of the first reachable endpoint is the reference
+	var err error
+	for _, n := range p.nodes {
+		p.check(ctx, n)
+		if errors.Is(n.lastErr, ErrChainIDMismatch) {
+			p.close()
+			return nil, n.lastErr
+		}
+		if !n.healthy {
+			err = n.lastErr
+		}
+	}
+
+	if p.elect(); p.primary == nil {
+		p.close()
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// run checks the health of all nodes every interval until the ctx is done
+func (p *nodePool) run(ctx context.Context, interval time.Duration) {
+	timer := time.NewTicker(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			checkCtx, cancel := context.WithTimeout(ctx, timeoutDuration)
+			p.checkAll(checkCtx)
+			cancel()
+		}
+	}
+}
+
+func (p *nodePool) checkAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, n := range p.nodes {
+		wg.Add(1)
+		go func(n *node) {
+			defer wg.Done()
+			p.check(ctx, n)
+		}(n)
+	}
+	wg.Wait()
+
+	p.elect()
+}
+
+func (p *nodePool) check(ctx context.Context, n *node) {
+	var (
+		head    *types.Header
+		latency time.Duration
+		syncing bool
+		peers   = int64(-1)
+		err     = n.dial(ctx)
+	)
+
+	if err == nil {
+		err = p.limiter.wait(ctx, "eth_getBlockByNumber")
+	}
+
+	if err == nil {
+		start := time.Now()
+		if head, err = n.eth.HeaderByNumber(ctx, nil); err != nil {
+			err = errors.Wrap(err, "failed to get head")
+		}
+		latency = time.Since(start)
+	}
+
+	if err == nil && !n.verified {
+		err = p.verifyChainID(ctx, n)
+	}
+
+	if err == nil {
+		syncing, peers, err = p.inspect(ctx, n, head)
+	}
+
+	n.Lock()
+	defer n.Unlock()
+
+	n.syncing = syncing
+	n.peers = peers
+	if head != nil {
+		n.head = head.Number.Uint64()
+		n.headTime = head.Time
+	}
+
+	if err != nil {
+		if n.healthy {
+			p.logger.Warn().Err(err).Msgf("node(%s) is unhealthy", n.endpoint)
+		}
+		n.healthy = false
+		n.lastErr = err
+		return
+	}
+
+	if !n.healthy && n.lastErr != nil {
+		p.logger.Info().Msgf("node(%s) is healthy again", n.endpoint)
+	}
+	n.healthy = true
+	n.latency = latency
+	n.lastErr = nil
+}
+
+func (p *nodePool) verifyChainID(ctx context.Context, n *node) error {
+	id, err := n.eth.ChainID(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get chain id")
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if p.chainID == nil {
+		p.chainID = id
+	} else if p.chainID.Cmp(id) != 0 {
+		return errors.Wrapf(ErrChainIDMismatch, "node(%s) chain id is %s, expected %s", n.endpoint, id, p.chainID)
+	}
+
+	n.Lock()
+	n.verified = true
+	n.Unlock()
+
+	return nil
+}
+
+// elect chooses the primary node. The current primary is kept as long as it is healthy
+// and not lagging behind the highest head more than maxLag blocks.
+func (p *nodePool) elect() {
+	nodes := p.healthyNodes()
+
+	p.Lock()
+	defer p.Unlock()
+
+	if len(nodes) == 0 {
+		return
+	}
+
+	best := nodes[0]
+	if p.primary != nil && p.primary != best {
+		p.primary.Lock()
+		healthy, head := p.primary.healthy, p.primary.head
+		p.primary.Unlock()
+
+		best.Lock()
+		bestHead := best.head
+		best.Unlock()
+
+		if healthy && head+p.maxLag >= bestHead {
+			return
+		}
+	}
+
+	if p.primary != nil && p.primary != best {
+		p.logger.Info().Msgf("primary node is switched to %s", best.endpoint)
+	}
+	p.primary = best
+}
+
+// healthyNodes returns the healthy nodes ordered by the highest head, then the lowest latency
+func (p *nodePool) healthyNodes() []*node {
+	type state struct {
+		n       *node
+		head    uint64
+		latency time.Duration
+	}
+
+	states := make([]state, 0, len(p.nodes))
+	for _, n := range p.nodes {
+		n.Lock()
+		if n.healthy {
+			states = append(states, state{n, n.head, n.latency})
+		}
+		n.Unlock()
+	}
+
+	sort.SliceStable(states, func(i, j int) bool {
+		if states[i].head != states[j].head {
+			return states[i].head > states[j].head
+		}
+		return states[i].latency < states[j].latency
+	})
+
+	nodes := make([]*node, len(states))
+	for i := range states {
+		nodes[i] = states[i].n
+	}
+	return nodes
+}
+
+// candidates returns the nodes to try in order, the primary comes first.
+// When no node is healthy, all dialed nodes are returned as the last resort.
+func (p *nodePool) candidates() []*node {
+	nodes := p.healthyNodes()
+
+	p.Lock()
+	primary := p.primary
+	p.Unlock()
+
+	if len(nodes) == 0 {
+		for _, n := range p.nodes {
+			n.Lock()
+			if n.rpc != nil {
+				nodes = append(nodes, n)
+			}
+			n.Unlock()
+		}
+		return nodes
+	}
+
+	for i := range nodes {
+		if nodes[i] == primary {
+			copy(nodes[1:i+1], nodes[:i])
+			nodes[0] = primary
+			break
+		}
+	}
+	return nodes
+}
+
+func (p *nodePool) markUnhealthy(n *node, err error) {
+	n.Lock()
+	if n.healthy {
+		p.logger.Warn().Err(err).Msgf("node(%s) is unhealthy", n.endpoint)
+	}
+	n.healthy = false
+	n.lastErr = err
+	n.Unlock()
+
+	p.elect()
+}
+
+// read runs fn against the primary node, and fails over to the next node when
+// the node can not be reached
+func (p *nodePool) read(ctx context.Context, method string, fn func(*node) error) error {
+	return p.retry(ctx, method, func() error {
+		if err := p.limiter.wait(ctx, method); err != nil {
+			return err
+		}
+
+		err := error(ErrNoHealthyNode)
+		for _, n := range p.candidates() {
+			if err = fn(n); err == nil || !isNodeFailure(ctx, err) {
+				return err
+			}
+			if isNodeDown(err) {
+				p.markUnhealthy(n, err)
+			}
+		}
+		return err
+	})
+}
+
+// broadcast runs fn against all healthy nodes concurrently.
+// It succeeds as long as one of the nodes accepts.
+func (p *nodePool) broadcast(ctx context.Context, method string, fn func(*node) error) error {
+	return p.retry(ctx, method, func() error {
+		if err := p.limiter.wait(ctx, method); err != nil {
+			return err
+		}
+		return p.broadcastOnce(ctx, fn)
+	})
+}
+
+func (p *nodePool) broadcastOnce(ctx context.Context, fn func(*node) error) error {
+	var (
+		nodes = p.candidates()
+		errs  = make([]error, len(nodes))
+		wg    sync.WaitGroup
+	)
+
+	if len(nodes) == 0 {
+		return ErrNoHealthyNode
+	}
+
+	for i := range nodes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = fn(nodes[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range errs {
+		if errs[i] == nil || isAlreadyKnown(errs[i]) {
+			return nil
+		}
+	}
+
+	for i := range errs {
+		if isNodeFailure(ctx, errs[i]) && isNodeDown(errs[i]) {
+			p.markUnhealthy(nodes[i], errs[i])
+		}
+	}
+
+	// the error of primary node is the most relevant
+	return errs[0]
+}
+
+func (p *nodePool) close() {
+	for _, n := range p.nodes {
+		n.close()
+	}
+}
+
+// isNodeFailure reports whether the err is caused by the node itself, rather than the request
+func isNodeFailure(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ethereum.NotFound) {
+		return false
+	}
+
+	var rpcErr rpc.Error
+	return !errors.As(err, &rpcErr)
+}
+
+// isNodeDown reports whether the node failure means the node can not serve at all.
+// The transient failures, such as rate limiting, server errors and timeouts, only fail over the request,
+// and the node is left to the health checker.
+func isNodeDown(err error) bool {
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return false
+	}
+
+	return !errors.Is(err, context.DeadlineExceeded) && !isRateLimited(err.Error())
+}
+
+func isAlreadyKnown(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "already known") || strings.Contains(msg, "known transaction")
+}
+
+// isNonceUsed reports whether the nonce of the rejected tx is taken by the tx on the chain or in the mempool
+func isNonceUsed(err error) bool {
+	return isAlreadyKnown(err) || strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
+
+func (p *nodePool) ChainID(ctx context.Context) (*big.Int, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.chainID == nil {
+		return nil, ErrNoHealthyNode
+	}
+	return new(big.Int).Set(p.chainID), nil
+}
+
+func (p *nodePool) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (nonce uint64, err error) {
+	err = p.read(ctx, "eth_getTransactionCount", func(n *node) (err error) {
+		nonce, err = n.eth.NonceAt(ctx, account, blockNumber)
+		return
+	})
+	return
+}
+
+func (p *nodePool) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (balance *big.Int, err error) {
+	err = p.read(ctx, "eth_getBalance", func(n *node) (err error) {
+		balance, err = n.eth.BalanceAt(ctx, account, blockNumber)
+		return
+	})
+	return
+}
+
+func (p *nodePool) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) (code []byte, err error) {
+	err = p.read(ctx, "eth_getCode", func(n *node) (err error) {
+		code, err = n.eth.CodeAt(ctx, account, blockNumber)
+		return
+	})
+	return
+}
+
+func (p *nodePool) HeaderByNumber(ctx context.Context, number *big.Int) (header *types.Header, err error) {
+	err = p.read(ctx, "eth_getBlockByNumber", func(n *node) (err error) {
+		header, err = n.eth.HeaderByNumber(ctx, number)
+		return
+	})
+	return
+}
+
+func (p *nodePool) HeaderByHash(ctx context.Context, hash common.Hash) (header *types.Header, err error) {
+	err = p.read(ctx, "eth_getBlockByHash", func(n *node) (err error) {
+		header, err = n.eth.HeaderByHash(ctx, hash)
+		return
+	})
+	return
+}
+
+func (p *nodePool) BlockByHash(ctx context.Context, hash common.Hash) (block *types.Block, err error) {
+	err = p.read(ctx, "eth_getBlockByHash", func(n *node) (err error) {
+		block, err = n.eth.BlockByHash(ctx, hash)
+		return
+	})
+	return
+}
+
+func (p *nodePool) TransactionReceipt(ctx context.Context, hash common.Hash) (receipt *types.Receipt, err error) {
+	err = p.read(ctx, "eth_getTransactionReceipt", func(n *node) (err error) {
+		receipt, err = n.eth.TransactionReceipt(ctx, hash)
+		return
+	})
+	return
+}
+
+func (p *nodePool) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) (output []byte, err error) {
+	err = p.read(ctx, "eth_call", func(n *node) (err error) {
+		output, err = n.eth.CallContract(ctx, msg, blockNumber)
+		return
+	})
+	return
+}
+
+func (p *nodePool) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (gas uint64, err error) {
+	err = p.read(ctx, "eth_estimateGas", func(n *node) (err error) {
+		gas, err = n.eth.EstimateGas(ctx, msg)
+		return
+	})
+	return
+}
+
+func (p *nodePool) SuggestGasTipCap(ctx context.Context) (tip *big.Int, err error) {
+	err = p.read(ctx, "eth_maxPriorityFeePerGas", func(n *node) (err error) {
+		tip, err = n.eth.SuggestGasTipCap(ctx)
+		return
+	})
+	return
+}
+
+func (p *nodePool) BatchCallContext(ctx context.Context, elems []rpc.BatchElem) error {
+	return p.read(ctx, "batch", func(n *node) error {
+		return n.rpc.BatchCallContext(ctx, elems)
+	})
+}
+
+// CallContext is the raw json-rpc call, for the methods which ethclient does not cover
+func (p *nodePool) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return p.read(ctx, method, func(n *node) error {
+		return n.rpc.CallContext(ctx, result, method, args...)
+	})
+}
+
+func (p *nodePool) PendingCodeAt(ctx context.Context, account common.Address) (code []byte, err error) {
+	err = p.read(ctx, "eth_getCode", func(n *node) (err error) {
+		code, err = n.eth.PendingCodeAt(ctx, account)
+		return
+	})
+	return
+}
+
+func (p *nodePool) PendingNonceAt(ctx context.Context, account common.Address) (nonce uint64, err error) {
+	err = p.read(ctx, "eth_getTransactionCount", func(n *node) (err error) {
+		nonce, err = n.eth.PendingNonceAt(ctx, account)
+		return
+	})
+	return
+}
+
+func (p *nodePool) FilterLogs(ctx context.Context, query ethereum.FilterQuery) (logs []types.Log, err error) {
+	err = p.read(ctx, "eth_getLogs", func(n *node) (err error) {
+		logs, err = n.eth.FilterLogs(ctx, query)
+		return
+	})
+	return
+}
+
+func (p *nodePool) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	nodes := p.candidates()
+	if len(nodes) == 0 {
+		return nil, ErrNoHealthyNode
+	}
+	return nodes[0].eth.SubscribeFilterLogs(ctx, query, ch)
+}
+
+// SubscribeNewHead subscribes on the primary node only, as the subscription is bound to the connection
+func (p *nodePool) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	nodes := p.candidates()
+	if len(nodes) == 0 {
+		return nil, ErrNoHealthyNode
+	}
+	return nodes[0].eth.SubscribeNewHead(ctx, ch)
+}
+
+func (p *nodePool) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return p.broadcast(ctx, MethodSendRawTransaction, func(n *node) error {
+		return n.eth.SendTransaction(ctx, tx)
+	})
+}
+
+func (p *nodePool) Close() {
+	p.close()
+}
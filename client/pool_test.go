@@ -0,0 +1,272 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *fakeRPCError) Error() string          { return e.Message }
+func (e *fakeRPCError) ErrorCode() int         { return e.Code }
+func (e *fakeRPCError) ErrorData() interface{} { return e.Data }
+
+type fakeHandler func(params []json.RawMessage) (interface{}, error)
+
+// fakeNode is a minimal json-rpc server to test the client without a running chain
+type fakeNode struct {
+	*httptest.Server
+	sync.Mutex
+	handlers map[string]fakeHandler
+	calls    map[string]int
+	statuses []int // http status codes responded to the next requests
+	requests int
+}
+
+func newFakeNode(chainID, head uint64) *fakeNode {
+	f := &fakeNode{
+		handlers: make(map[string]fakeHandler),
+		calls:    make(map[string]int),
+	}
+	f.handle("eth_chainId", func([]json.RawMessage) (interface{}, error) {
+		return hexutil.Uint64(chainID), nil
+	})
+	f.handle("eth_blockNumber", func([]json.RawMessage) (interface{}, error) {
+		return hexutil.Uint64(head), nil
+	})
+	f.handle("eth_getBlockByNumber", func([]json.RawMessage) (interface{}, error) {
+		return &types.Header{
+			Number:     new(big.Int).SetUint64(head),
+			Difficulty: big.NewInt(0),
+			Time:       uint64(time.Now().Unix()),
+		}, nil
+	})
+	f.handle("eth_syncing", func([]json.RawMessage) (interface{}, error) {
+		return false, nil
+	})
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+func (f *fakeNode) handle(method string, h fakeHandler) {
+	f.Lock()
+	f.handlers[method] = h
+	f.Unlock()
+}
+
+// failNext responds the next n requests with the http status
+func (f *fakeNode) failNext(status, n int) {
+	f.Lock()
+	for i := 0; i < n; i++ {
+		f.statuses = append(f.statuses, status)
+	}
+	f.Unlock()
+}
+
+func (f *fakeNode) called(method string) int {
+	f.Lock()
+	defer f.Unlock()
+	return f.calls[method]
+}
+
+func (f *fakeNode) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	type response struct {
+		Version string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Result  interface{}     `json:"result,omitempty"`
+		Error   *fakeRPCError   `json:"error,omitempty"`
+	}
+
+	body, _ := io.ReadAll(r.Body)
+
+	f.Lock()
+	f.requests++
+	if len(f.statuses) > 0 {
+		status := f.statuses[0]
+		f.statuses = f.statuses[1:]
+		f.Unlock()
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	f.Unlock()
+
+	var (
+		reqs  []request
+		batch = len(bytes.TrimSpace(body)) > 0 && bytes.TrimSpace(body)[0] == '['
+	)
+	if batch {
+		_ = json.Unmarshal(body, &reqs)
+	} else {
+		var req request
+		_ = json.Unmarshal(body, &req)
+		reqs = []request{req}
+	}
+
+	resps := make([]response, len(reqs))
+	for i, req := range reqs {
+		f.Lock()
+		h, ok := f.handlers[req.Method]
+		f.calls[req.Method]++
+		f.Unlock()
+
+		resps[i] = response{Version: "2.0", ID: req.ID}
+		if !ok {
+			resps[i].Error = &fakeRPCError{Code: -32601, Message: "the method " + req.Method + " does not exist/is not available"}
+			continue
+		}
+
+		result, err := h(req.Params)
+		if err != nil {
+			resps[i].Error = &fakeRPCError{Code: -32000, Message: err.Error()}
+			if rpcErr, ok := err.(*fakeRPCError); ok {
+				resps[i].Error = rpcErr
+			}
+			continue
+		}
+		if result == nil {
+			result = json.RawMessage("null")
+		}
+		resps[i].Result = result
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if batch {
+		_ = json.NewEncoder(w).Encode(resps)
+	} else {
+		_ = json.NewEncoder(w).Encode(resps[0])
+	}
+}
+
+var testPoolConfig = poolConfig{maxLag: DefaultMaxHeadLag, logger: DefaultLogger}
+
+func TestNodePoolFailover(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		primary = newFakeNode(1010, 10)
+		backup  = newFakeNode(1010, 9)
+		account = common.HexToAddress(TestAccount)
+	)
+	defer backup.Close()
+
+	for _, f := range []*fakeNode{primary, backup} {
+		f.handle("eth_getBalance", func([]json.RawMessage) (interface{}, error) {
+			return (*hexutil.Big)(big.NewInt(100)), nil
+		})
+	}
+
+	p, err := newNodePool(ctx, []string{primary.URL, backup.URL}, testPoolConfig)
+	require.NoError(t, err)
+	defer p.Close()
+
+	require.Equal(t, primary.URL, p.candidates()[0].endpoint)
+
+	_, err = p.BalanceAt(ctx, account, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, primary.called("eth_getBalance"))
+
+	primary.Close()
+
+	balance, err := p.BalanceAt(ctx, account, nil)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(100), balance)
+	require.Equal(t, 1, backup.called("eth_getBalance"))
+	require.Equal(t, backup.URL, p.candidates()[0].endpoint)
+}
+
+func TestNodePoolTransientFailure(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		f       = newFakeNode(1010, 10)
+		account = common.HexToAddress(TestAccount)
+	)
+	defer f.Close()
+
+	f.handle("eth_getBalance", func([]json.RawMessage) (interface{}, error) {
+		return (*hexutil.Big)(big.NewInt(100)), nil
+	})
+
+	p, err := newNodePool(ctx, []string{f.URL}, testPoolConfig)
+	require.NoError(t, err)
+	defer p.Close()
+	p.retryPolicy = RetryPolicy{MaxAttempts: 1}
+
+	// the rate limiting and the server errors leave the node to the health checker
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		f.failNext(status, 1)
+		_, err = p.BalanceAt(ctx, account, nil)
+		require.Error(t, err)
+		require.True(t, p.isHealthy())
+	}
+
+	// the node which can not be reached is excluded
+	f.Close()
+	_, err = p.BalanceAt(ctx, account, nil)
+	require.Error(t, err)
+	require.False(t, p.isHealthy())
+}
+
+func TestNodePoolChainIDMismatch(t *testing.T) {
+	var (
+		ctx = context.Background()
+		a   = newFakeNode(1010, 10)
+		b   = newFakeNode(1, 10)
+	)
+	defer a.Close()
+	defer b.Close()
+
+	_, err := newNodePool(ctx, []string{a.URL, b.URL}, testPoolConfig)
+	require.ErrorIs(t, err, ErrChainIDMismatch)
+}
+
+func TestNodePoolBroadcast(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		a     = newFakeNode(1010, 10)
+		b     = newFakeNode(1010, 10)
+		key   = crypto.ToECDSAUnsafe(common.FromHex(TestPrivKey))
+		to, _ = GenerateAddr()
+	)
+	defer a.Close()
+	defer b.Close()
+
+	a.handle("eth_sendRawTransaction", func([]json.RawMessage) (interface{}, error) {
+		return nil, &fakeRPCError{Code: -32000, Message: "already known"}
+	})
+	b.handle("eth_sendRawTransaction", func([]json.RawMessage) (interface{}, error) {
+		return common.Hash{}, nil
+	})
+
+	p, err := newNodePool(ctx, []string{a.URL, b.URL}, testPoolConfig)
+	require.NoError(t, err)
+	defer p.Close()
+
+	tx, err := types.SignNewTx(key, types.NewLondonSigner(big.NewInt(1010)), &types.LegacyTx{To: &to, Gas: 21000, GasPrice: big.NewInt(1)})
+	require.NoError(t, err)
+
+	require.NoError(t, p.SendTransaction(ctx, tx))
+	require.Equal(t, 1, a.called("eth_sendRawTransaction"))
+	require.Equal(t, 1, b.called("eth_sendRawTransaction"))
+}
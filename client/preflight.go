@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrTxReverted = errors.New("tx reverted in preflight")
+
+	// Panic(uint256)
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+)
+
+// RevertError is the revert of the preflight call, which matches ErrTxReverted with errors.Is
+type RevertError struct {
+	Reason string // the decoded reason, or the message of the node when the data is absent
+	Data   []byte
+}
+
+func (e *RevertError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrTxReverted, e.Reason)
+}
+
+func (e *RevertError) Is(target error) bool {
+	return target == ErrTxReverted
+}
+
+// preflightTx simulates the signed tx by eth_call at the pending block,
+// so that the tx which will revert is not broadcasted
+func (c *Client) preflightTx(ctx context.Context, tx *types.Transaction) error {
+	from, err := types.Sender(types.NewLondonSigner(c.chainID), tx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get sender")
+	}
+
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		msg.GasTipCap, msg.GasFeeCap = tx.GasTipCap(), tx.GasFeeCap()
+	} else {
+		msg.GasPrice = tx.GasPrice()
+	}
+
+	var output hexutil.Bytes
+	if err = c.pool.CallContext(ctx, &output, "eth_call", toCallArg(msg), BlockPending); err != nil {
+		if revert := toRevertError(err); revert != nil {
+			return revert
+		}
+		return errors.Wrap(err, "failed to preflight")
+	}
+
+	return nil
+}
+
+func toRevertError(err error) *RevertError {
+	var (
+		revert  = &RevertError{Reason: err.Error()}
+		dataErr rpc.DataError
+	)
+
+	if errors.As(err, &dataErr) {
+		if data, ok := dataErr.ErrorData().(string); ok {
+			revert.Data, _ = hexutil.Decode(data)
+		}
+	}
+
+	if len(revert.Data) == 0 {
+		if !strings.Contains(strings.ToLower(revert.Reason), "revert") {
+			return nil
+		}
+		return revert
+	}
+
+	if reason, err := abi.UnpackRevert(revert.Data); err == nil {
+		revert.Reason = reason
+	} else if len(revert.Data) == 4+32 && string(revert.Data[:4]) == string(panicSelector) {
+		revert.Reason = fmt.Sprintf("panic(0x%x)", new(big.Int).SetBytes(revert.Data[4:]))
+	}
+
+	return revert
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreflight(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		f     = newFakeNode(1010, 10)
+		to, _ = GenerateAddr()
+		// Error("insufficient balance")
+		revertData = "0x08c379a0" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"0000000000000000000000000000000000000000000000000000000000000014" +
+			"696e73756666696369656e742062616c616e6365000000000000000000000000"
+		reverts = true
+		params  []json.RawMessage
+	)
+	defer f.Close()
+
+	f.handle("eth_getTransactionCount", func([]json.RawMessage) (interface{}, error) {
+		return hexutil.Uint64(3), nil
+	})
+	f.handle("eth_call", func(p []json.RawMessage) (interface{}, error) {
+		params = p
+		if reverts {
+			return nil, &fakeRPCError{Code: 3, Message: "execution reverted: insufficient balance", Data: revertData}
+		}
+		return hexutil.Bytes{}, nil
+	})
+	f.handle(MethodSendRawTransaction, func([]json.RawMessage) (interface{}, error) {
+		return common.Hash{}, nil
+	})
+
+	c, err := NewClient(ctx, f.URL, nil, WithPreflight(true))
+	require.NoError(t, err)
+
+	_, err = c.AsyncSend(ctx, TestPrivKey, &to, ToWei(1.0, 9), nil, 21000)
+	require.ErrorIs(t, err, ErrTxReverted)
+
+	var revert *RevertError
+	require.ErrorAs(t, err, &revert)
+	require.Equal(t, "insufficient balance", revert.Reason)
+	require.JSONEq(t, `"pending"`, string(params[1]))
+	require.Equal(t, 0, f.called(MethodSendRawTransaction))
+
+	_, err = c.SyncSend(ctx, TestPrivKey, &to, ToWei(1.0, 9), nil, 21000)
+	require.ErrorIs(t, err, ErrTxReverted)
+
+	// the nonce is released to be reused
+	reverts = false
+	_, err = c.AsyncSend(ctx, TestPrivKey, &to, ToWei(1.0, 9), nil, 21000)
+	require.NoError(t, err)
+	require.Equal(t, 1, f.called(MethodSendRawTransaction))
+
+	nonce, err := c.NonceCash(ctx, TestPrivKey)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), nonce)
+}
+
+func TestToRevertError(t *testing.T) {
+	// Panic(0x11), arithmetic overflow
+	revert := toRevertError(&fakeRPCError{Code: 3, Message: "execution reverted", Data: "0x4e487b710000000000000000000000000000000000000000000000000000000000000011"})
+	require.Equal(t, "panic(0x11)", revert.Reason)
+
+	revert = toRevertError(&fakeRPCError{Code: -32000, Message: "VM Exception while processing transaction: revert"})
+	require.Equal(t, "VM Exception while processing transaction: revert", revert.Reason)
+
+	require.Nil(t, toRevertError(&fakeRPCError{Code: -32000, Message: "header not found"}))
+}
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type RateLimitStats struct {
+	ReadCalls      uint64
+	ReadThrottled  uint64
+	ReadWait       time.Duration
+	WriteCalls     uint64
+	WriteThrottled uint64
+	WriteWait      time.Duration
+}
+
+// tokenBucket refills rate tokens per second up to burst.
+// The tokens go negative while the callers are waiting, so the callers are served in order.
+type tokenBucket struct {
+	sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+}
+
+// take consumes a token only if it is available now
+func (b *tokenBucket) take() bool {
+	b.Lock()
+	defer b.Unlock()
+
+	b.refill(time.Now())
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// reserve consumes a token, and returns how long the caller should wait for it
+func (b *tokenBucket) reserve() time.Duration {
+	b.Lock()
+	defer b.Unlock()
+
+	b.refill(time.Now())
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns the reserved token
+func (b *tokenBucket) cancel() {
+	b.Lock()
+	b.tokens++
+	b.Unlock()
+}
+
+// rateLimiter has the separate budgets for reads and writes.
+// Writes are prioritized, which borrow the spare read token instead of waiting for the write token.
+type rateLimiter struct {
+	read  *tokenBucket
+	write *tokenBucket
+
+	readCalls      uint64
+	readThrottled  uint64
+	readWait       int64
+	writeCalls     uint64
+	writeThrottled uint64
+	writeWait      int64
+}
+
+func newRateLimiter(readRate float64, readBurst int, writeRate float64, writeBurst int) *rateLimiter {
+	return &rateLimiter{
+		read:  newTokenBucket(readRate, readBurst),
+		write: newTokenBucket(writeRate, writeBurst),
+	}
+}
+
+func (l *rateLimiter) wait(ctx context.Context, method string) error {
+	if method == MethodSendRawTransaction {
+		atomic.AddUint64(&l.writeCalls, 1)
+		if l.write == nil {
+			return nil
+		}
+		if l.write.take() || (l.read != nil && l.read.take()) {
+			return nil
+		}
+		return l.sleep(ctx, l.write, &l.writeThrottled, &l.writeWait)
+	}
+
+	atomic.AddUint64(&l.readCalls, 1)
+	if l.read == nil {
+		return nil
+	}
+	return l.sleep(ctx, l.read, &l.readThrottled, &l.readWait)
+}
+
+func (l *rateLimiter) sleep(ctx context.Context, b *tokenBucket, throttled *uint64, total *int64) error {
+	delay := b.reserve()
+	if delay == 0 {
+		return nil
+	}
+
+	atomic.AddUint64(throttled, 1)
+	atomic.AddInt64(total, int64(delay))
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (l *rateLimiter) stats() RateLimitStats {
+	return RateLimitStats{
+		ReadCalls:      atomic.LoadUint64(&l.readCalls),
+		ReadThrottled:  atomic.LoadUint64(&l.readThrottled),
+		ReadWait:       time.Duration(atomic.LoadInt64(&l.readWait)),
+		WriteCalls:     atomic.LoadUint64(&l.writeCalls),
+		WriteThrottled: atomic.LoadUint64(&l.writeThrottled),
+		WriteWait:      time.Duration(atomic.LoadInt64(&l.writeWait)),
+	}
+}
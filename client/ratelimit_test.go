@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiterRead(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		l     = newRateLimiter(20, 2, 0, 0)
+		start = time.Now()
+	)
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, l.wait(ctx, "eth_getBalance"))
+	}
+
+	// 2 calls are in the burst, the rest wait for 50ms each
+	require.True(t, time.Since(start) >= 90*time.Millisecond)
+
+	stats := l.stats()
+	require.Equal(t, uint64(4), stats.ReadCalls)
+	require.Equal(t, uint64(2), stats.ReadThrottled)
+	require.Equal(t, uint64(0), stats.WriteCalls)
+}
+
+func TestRateLimiterWritePriority(t *testing.T) {
+	var (
+		ctx = context.Background()
+		l   = newRateLimiter(1, 2, 1, 1)
+	)
+
+	require.NoError(t, l.wait(ctx, MethodSendRawTransaction))
+	// borrow the spare read token
+	require.NoError(t, l.wait(ctx, MethodSendRawTransaction))
+	require.Equal(t, uint64(0), l.stats().WriteThrottled)
+
+	// reads can not use the write budget
+	require.NoError(t, l.wait(ctx, "eth_call"))
+	require.Equal(t, uint64(0), l.stats().ReadThrottled)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, l.wait(timeoutCtx, "eth_call"), context.DeadlineExceeded)
+	require.Equal(t, uint64(1), l.stats().ReadThrottled)
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	l := newRateLimiter(0, 0, 0, 0)
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, l.wait(context.Background(), "eth_call"))
+	}
+	require.Equal(t, uint64(0), l.stats().ReadThrottled)
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrNonceGap         = errors.New("nonce is ahead of the next nonce")
+)
+
+// SendRawTx sends the externally signed raw tx through the confirmer, without waiting the confirmation.
+// The nonce of the tx is registered to the nonce cache of the sender, and given back when the send failed.
+// The tx whose nonce is ahead of the next nonce of the sender is rejected by ErrNonceGap,
+// because the nonces in the gap would never be filled by the client.
+func (c *Client) SendRawTx(ctx context.Context, rawTx string) (hash string, err error) {
+	tx, from, err := c.decodeSignedTx(rawTx)
+	if err != nil {
+		return
+	}
+
+	release, err := c.nonceCash.Observe(ctx, from, tx.Nonce(), c)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to observe nonce(=%d) of %s", tx.Nonce(), from)
+		return
+	}
+
+	err = c.enqueueSignedTx(ctx, tx)
+	release(err)
+	if err != nil {
+		return
+	}
+
+	hash = tx.Hash().Hex()
+	return
+}
+
+// SyncSendRaw sends the externally signed raw tx, and waits for the confirmation like SyncSend
+func (c *Client) SyncSendRaw(ctx context.Context, rawTx string) (hash string, err error) {
+	if hash, err = c.SendRawTx(ctx, rawTx); err != nil {
+		return
+	}
+
+	err = c.waitConfirmed(ctx, hash)
+	return
+}
+
+// decodeSignedTx decodes the raw tx, then validates the chain id and the signature
+func (c *Client) decodeSignedTx(rawTx string) (*types.Transaction, common.Address, error) {
+	tx, err := DecodeRawTx(rawTx)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+
+	if v, r, s := tx.RawSignatureValues(); v.Sign() == 0 && r.Sign() == 0 && s.Sign() == 0 {
+		return nil, common.Address{}, ErrUnsignedTx
+	}
+
+	if !tx.Protected() {
+		return nil, common.Address{}, errors.Wrap(ErrChainIDMismatch, "tx is not replay protected")
+	}
+	if tx.ChainId().Cmp(c.chainID) != 0 {
+		return nil, common.Address{}, errors.Wrapf(ErrChainIDMismatch, "tx chain id is %v, expected %v", tx.ChainId(), c.chainID)
+	}
+
+	from, err := types.Sender(types.NewLondonSigner(c.chainID), tx)
+	if err != nil {
+		return nil, common.Address{}, errors.Wrap(ErrInvalidSignature, err.Error())
+	}
+
+	return tx, from, nil
+}
+
+func (c *Client) enqueueSignedTx(ctx context.Context, tx *types.Transaction) error {
+	if !c.pool.isHealthy() {
+		return ErrNoHealthyNode
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeoutDuration)
+	defer cancel()
+
+	if c.preflight {
+		if err := c.preflightTx(timeoutCtx, tx); err != nil {
+			return err
+		}
+	}
+
+	if err := c.confirmer.EnqueueTx(timeoutCtx, tx); err != nil {
+		return errors.Wrapf(err, "failed to enqueue tx(%v)", tx.Hash())
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	"github.com/tak1827/transaction-confirmer/confirm"
+)
+
+func TestSendRawTx(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		f     = newFakeNode(1010, 10)
+		from  = common.HexToAddress(TestAccount)
+		to, _ = GenerateAddr()
+		sent  *types.Transaction
+		fails bool
+	)
+	defer f.Close()
+
+	f.handle("eth_getTransactionCount", func([]json.RawMessage) (interface{}, error) {
+		return hexutil.Uint64(3), nil
+	})
+	f.handle(MethodSendRawTransaction, func(params []json.RawMessage) (interface{}, error) {
+		if fails {
+			return nil, &fakeRPCError{Code: -32000, Message: "replacement transaction underpriced"}
+		}
+		var raw hexutil.Bytes
+		_ = json.Unmarshal(params[0], &raw)
+		sent = new(types.Transaction)
+		if err := sent.UnmarshalBinary(raw); err != nil {
+			return nil, err
+		}
+		return sent.Hash(), nil
+	})
+	f.handle(MethodGetTransactionReceipt, func(params []json.RawMessage) (interface{}, error) {
+		var hash common.Hash
+		_ = json.Unmarshal(params[0], &hash)
+		return &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: hash, BlockNumber: big.NewInt(8), Logs: []*types.Log{}}, nil
+	})
+
+	c, err := NewClient(ctx, f.URL, []confirm.Opt{confirm.WithWorkerInterval(10)}, WithSyncSendConfirmInterval(10))
+	require.NoError(t, err)
+	c.Start()
+	defer c.Stop()
+
+	rawTx := func(chainID *big.Int, nonce uint64) string {
+		tx := types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(2),
+			Gas:       21000,
+			To:        &to,
+			Value:     big.NewInt(1),
+		})
+		signed, err := SignOffline(tx, chainID, TestPrivKey)
+		require.NoError(t, err)
+		raw, err := EncodeRawTx(signed)
+		require.NoError(t, err)
+		return raw
+	}
+
+	// signed by the other chain id
+	_, err = c.SendRawTx(ctx, rawTx(big.NewInt(1), 7))
+	require.ErrorIs(t, err, ErrChainIDMismatch)
+
+	// replay unprotected
+	privKey, err := crypto.HexToECDSA(TestPrivKey)
+	require.NoError(t, err)
+	legacy, err := types.SignTx(types.NewTransaction(7, to, big.NewInt(1), 21000, big.NewInt(1), nil), types.HomesteadSigner{}, privKey)
+	require.NoError(t, err)
+	raw, err := EncodeRawTx(legacy)
+	require.NoError(t, err)
+	_, err = c.SendRawTx(ctx, raw)
+	require.ErrorIs(t, err, ErrChainIDMismatch)
+
+	// the failed send gives back the observed nonce
+	fails = true
+	_, err = c.SendRawTx(ctx, rawTx(c.ChainID(), 3))
+	require.Error(t, err)
+	fails = false
+	_, err = c.AsyncSend(ctx, TestPrivKey, &to, big.NewInt(1), nil, 21000)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), sent.Nonce())
+
+	// the nonce ahead of the next one is rejected, and the gap is not skipped
+	_, err = c.SendRawTx(ctx, rawTx(c.ChainID(), 7))
+	require.ErrorIs(t, err, ErrNonceGap)
+
+	// the externally signed tx of the next nonce is sent
+	raw = rawTx(c.ChainID(), 4)
+	decoded, err := DecodeRawTx(raw)
+	require.NoError(t, err)
+	hash, err := c.SyncSendRaw(ctx, raw)
+	require.NoError(t, err)
+	require.Equal(t, decoded.Hash().Hex(), hash)
+
+	// the nonce of the sender is assigned after the observed one
+	tx, err := c.BuildTx(ctx, from, &to, big.NewInt(1), nil, 21000)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), tx.Nonce())
+
+	// the managed send of the same account also goes after it
+	_, err = c.SyncSend(ctx, TestPrivKey, &to, big.NewInt(1), nil, 21000)
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), sent.Nonce())
+}
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+const (
+	MethodSendRawTransaction = "eth_sendRawTransaction"
+)
+
+var (
+	DefaultRetryPolicy = RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+		Jitter:      0.2,
+		Retryable:   IsRetryableError,
+	}
+	// the tx might be accepted by the node even when the response is lost,
+	// so the resend is allowed only when the node surely did not process the tx
+	DefaultSendRetryPolicy = RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+		Jitter:      0.2,
+		Retryable:   IsSafeToResend,
+	}
+)
+
+type RetryPolicy struct {
+	MaxAttempts int           // the total number of attempts, 1 disables retry
+	BaseDelay   time.Duration // the delay before the first retry, doubled on every retry
+	MaxDelay    time.Duration
+	Jitter      float64 // randomize the delay by this fraction, between 0 and 1
+	Retryable   func(err error) bool
+}
+
+// Backoff returns the delay before the retry of given attempt, attempt starts from 1
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(float64(delay) * p.Jitter * rand.Float64())
+	}
+	return delay
+}
+
+// retry runs fn until it succeeds, or the error is not retryable by the policy of the method
+func (p *nodePool) retry(ctx context.Context, method string, fn func() error) (err error) {
+	policy, ok := p.retryPolicies[method]
+	if !ok {
+		policy = p.retryPolicy
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil {
+			return
+		}
+
+		if attempt >= policy.MaxAttempts || policy.Retryable == nil || !policy.Retryable(err) {
+			return
+		}
+
+		delay := policy.Backoff(attempt)
+		p.logger.Debug().Err(err).Msgf("retry %s after %s, attempt=%d", method, delay, attempt)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// IsRetryableError reports whether the err is transient, such as timeouts, rate limiting,
+// server errors and dropped connections
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if IsSafeToResend(err) {
+		return true
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// IsSafeToResend reports whether the err proves the request was not processed by the node
+func IsSafeToResend(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == 429
+	}
+
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		// -32005 is the limit exceeded error of infura and the others,
+		// which is also used for the too large log range that never succeeds on resend
+		return (rpcErr.ErrorCode() == -32005 && !isLogRangeExceeded(rpcErr.Error())) || isRateLimited(rpcErr.Error())
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+func isRateLimited(msg string) bool {
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "rate limit") || strings.Contains(msg, "too many requests")
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryTransientError(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		f       = newFakeNode(1010, 10)
+		account = common.HexToAddress(TestAccount)
+	)
+	defer f.Close()
+
+	f.handle("eth_getBalance", func([]json.RawMessage) (interface{}, error) {
+		return (*hexutil.Big)(big.NewInt(100)), nil
+	})
+
+	p, err := newNodePool(ctx, []string{f.URL}, testPoolConfig)
+	require.NoError(t, err)
+	defer p.Close()
+	p.retryPolicy.BaseDelay = time.Millisecond
+
+	f.failNext(http.StatusServiceUnavailable, 2)
+
+	balance, err := p.BalanceAt(ctx, account, nil)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(100), balance)
+	require.Equal(t, 1, f.called("eth_getBalance"))
+
+	f.failNext(http.StatusServiceUnavailable, 3)
+
+	_, err = p.BalanceAt(ctx, account, nil)
+	require.Error(t, err)
+}
+
+func TestRetrySendTransaction(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		f     = newFakeNode(1010, 10)
+		key   = crypto.ToECDSAUnsafe(common.FromHex(TestPrivKey))
+		to, _ = GenerateAddr()
+	)
+	defer f.Close()
+
+	f.handle("eth_sendRawTransaction", func([]json.RawMessage) (interface{}, error) {
+		return common.Hash{}, nil
+	})
+
+	p, err := newNodePool(ctx, []string{f.URL}, testPoolConfig)
+	require.NoError(t, err)
+	defer p.Close()
+	p.retryPolicies[MethodSendRawTransaction] = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, Retryable: IsSafeToResend}
+
+	tx, err := types.SignNewTx(key, types.NewLondonSigner(big.NewInt(1010)), &types.LegacyTx{To: &to, Gas: 21000, GasPrice: big.NewInt(1)})
+	require.NoError(t, err)
+
+	// the node might have processed the tx
+	f.failNext(http.StatusBadGateway, 1)
+	require.Error(t, p.SendTransaction(ctx, tx))
+
+	// rate limited requests are not processed
+	f.failNext(http.StatusTooManyRequests, 1)
+	require.NoError(t, p.SendTransaction(ctx, tx))
+	require.Equal(t, 1, f.called("eth_sendRawTransaction"))
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	require.Equal(t, 100*time.Millisecond, policy.Backoff(1))
+	require.Equal(t, 200*time.Millisecond, policy.Backoff(2))
+	require.Equal(t, 400*time.Millisecond, policy.Backoff(3))
+	require.Equal(t, time.Second, policy.Backoff(10))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := policy.Backoff(2)
+		require.True(t, 100*time.Millisecond <= delay && delay <= 200*time.Millisecond)
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	cases := []struct {
+		err       error
+		retryable bool
+		resend    bool
+	}{
+		{rpc.HTTPError{StatusCode: 429}, true, true},
+		{rpc.HTTPError{StatusCode: 503}, true, false},
+		{rpc.HTTPError{StatusCode: 400}, false, false},
+		{&fakeRPCError{Code: -32005, Message: "limit exceeded"}, true, true},
+		{&fakeRPCError{Code: -32000, Message: "nonce too low"}, false, false},
+		{errors.Wrap(syscall.ECONNRESET, "read"), true, false},
+		{errors.Wrap(syscall.ECONNREFUSED, "dial"), true, true},
+		{context.DeadlineExceeded, true, false},
+		{context.Canceled, false, false},
+	}
+
+	for _, c := range cases {
+		require.Equal(t, c.retryable, IsRetryableError(c.err), c.err.Error())
+		require.Equal(t, c.resend, IsSafeToResend(c.err), c.err.Error())
+	}
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"crypto/ecdsa"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+var _ Signer = (*KeySigner)(nil)
+
+// Signer signs the digest on behalf of the account, which can be backed by the remote signer or the hsm
+type Signer interface {
+	Address() common.Address
+	// SignHash returns the signature in the [R || S || V] format, where V is 0 or 1
+	SignHash(hash common.Hash) ([]byte, error)
+}
+
+// KeySigner is the Signer by the raw private key
+type KeySigner struct {
+	privKey *ecdsa.PrivateKey
+	address common.Address
+}
+
+func NewKeySigner(priv string) (*KeySigner, error) {
+	privKey, err := crypto.HexToECDSA(priv)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse private key")
+	}
+
+	return &KeySigner{
+		privKey: privKey,
+		address: crypto.PubkeyToAddress(privKey.PublicKey),
+	}, nil
+}
+
+func (s *KeySigner) Address() common.Address {
+	return s.address
+}
+
+func (s *KeySigner) SignHash(hash common.Hash) ([]byte, error) {
+	sig, err := crypto.Sign(hash.Bytes(), s.privKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "at crypto.Sign")
+	}
+	return sig, nil
+}
+
+// recoverSigner recovers the address from the signature, where V is either 0/1 or 27/28
+func recoverSigner(hash common.Hash, sig []byte) (common.Address, error) {
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, errors.Wrapf(ErrInvalidSignature, "signature length is %d", len(sig))
+	}
+
+	sig = common.CopyBytes(sig)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pub, err := crypto.SigToPub(hash.Bytes(), sig)
+	if err != nil {
+		return common.Address{}, errors.Wrap(ErrInvalidSignature, err.Error())
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
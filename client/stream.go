@@ -0,0 +1,336 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultMaxReorgDepth = 64
+)
+
+var (
+	ErrReorgTooDeep = errors.New("reorg is deeper than the max reorg depth")
+	ErrRetry        = errors.New("retry the block event")
+)
+
+type BlockEventType int
+
+const (
+	Added BlockEventType = iota
+	Removed
+)
+
+func (t BlockEventType) String() string {
+	if t == Removed {
+		return "removed"
+	}
+	return "added"
+}
+
+type BlockPointer struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+}
+
+// BlockEvent is emitted in the order of the chain. The Header is nil for Removed,
+// and the Logs of Removed are marked as removed, which are empty when the node dropped them.
+type BlockEvent struct {
+	Type   BlockEventType
+	Block  BlockPointer
+	Header *types.Header
+	Logs   []types.Log
+}
+
+// Cursor persists the tracked blocks of the stream, from the oldest to the latest processed
+type Cursor interface {
+	Load() ([]BlockPointer, error)
+	Save(blocks []BlockPointer) error
+}
+
+type BlockStreamConfig struct {
+	// the first block streamed when the cursor is empty
+	From uint64
+	// the blocks are streamed after confirmed by this number of blocks.
+	// The deeper reorg than this is still detected, and the blocks are removed.
+	Confirmations uint64
+	// the logs of each block are fetched by the addresses and topics of the query, when not nil
+	Logs *ethereum.FilterQuery
+	// the stream starts from the beginning when nil
+	Cursor Cursor
+	// the number of the blocks tracked to detect the reorg, and the deeper reorg stops the stream
+	MaxReorgDepth int
+}
+
+// BlockStream streams the blocks and the logs, and emits Removed on reorg by tracking the parent hashes
+type BlockStream struct {
+	client *Client
+	cfg    BlockStreamConfig
+	blocks []BlockPointer
+	// the number of the blocks removed by the ongoing reorg
+	removed int
+}
+
+func (c *Client) NewBlockStream(cfg BlockStreamConfig) (*BlockStream, error) {
+	if cfg.MaxReorgDepth <= 0 {
+		cfg.MaxReorgDepth = DefaultMaxReorgDepth
+	}
+
+	s := &BlockStream{client: c, cfg: cfg}
+	if cfg.Cursor != nil {
+		blocks, err := cfg.Cursor.Load()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load cursor")
+		}
+		s.blocks = blocks
+	}
+
+	return s, nil
+}
+
+// Latest returns the latest processed block
+func (s *BlockStream) Latest() (BlockPointer, bool) {
+	if len(s.blocks) == 0 {
+		return BlockPointer{}, false
+	}
+	return s.blocks[len(s.blocks)-1], true
+}
+
+// Run streams the blocks until the ctx is done or the handler fails.
+// The cursor is saved after each event is handled, so the failed event is redelivered on the next run.
+// The handler can return the error matching ErrRetry, such as the one wrapped by Retryable,
+// to redeliver the event on the next poll without stopping the stream.
+func (s *BlockStream) Run(ctx context.Context, handler func(BlockEvent) error) error {
+	ticker := time.NewTicker(time.Duration(s.client.headPollInterval) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		if err := s.catchUp(ctx, handler); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			var hErr *handlerError
+			if errors.Is(err, ErrReorgTooDeep) || errors.As(err, &hErr) {
+				return err
+			}
+			s.client.logger.Warn().Err(err).Msg("failed to stream blocks")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.client.heads.next():
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *BlockStream) catchUp(ctx context.Context, handler func(BlockEvent) error) error {
+	latest, err := s.client.LatestBlockNumber(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get latest block number")
+	}
+	if latest < s.cfg.Confirmations {
+		return nil
+	}
+	target := latest - s.cfg.Confirmations
+
+	for {
+		next := s.cfg.From
+		if tip, ok := s.Latest(); ok {
+			next = tip.Number + 1
+		}
+		if next > target {
+			return nil
+		}
+
+		header, err := s.client.pool.HeaderByNumber(ctx, new(big.Int).SetUint64(next))
+		if err != nil {
+			return errors.Wrapf(err, "failed to get header(=%d)", next)
+		}
+
+		if tip, ok := s.Latest(); ok && header.ParentHash != tip.Hash {
+			if err = s.remove(ctx, handler, tip); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err = s.add(ctx, handler, header); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *BlockStream) add(ctx context.Context, handler func(BlockEvent) error, header *types.Header) error {
+	ev := BlockEvent{
+		Type:   Added,
+		Block:  BlockPointer{Number: header.Number.Uint64(), Hash: header.Hash()},
+		Header: header,
+	}
+
+	logs, err := s.logs(ctx, ev.Block.Hash)
+	if err != nil {
+		return err
+	}
+	ev.Logs = logs
+
+	if err = handler(ev); err != nil {
+		return wrapHandlerError(err, ev)
+	}
+
+	s.blocks = append(s.blocks, ev.Block)
+	s.removed = 0
+	if len(s.blocks) > s.cfg.MaxReorgDepth {
+		s.blocks = s.blocks[len(s.blocks)-s.cfg.MaxReorgDepth:]
+	}
+	return s.save()
+}
+
+func (s *BlockStream) remove(ctx context.Context, handler func(BlockEvent) error, tip BlockPointer) error {
+	if s.removed >= s.cfg.MaxReorgDepth {
+		return errors.Wrapf(ErrReorgTooDeep, "block(=%d)", tip.Number)
+	}
+
+	// the parent of the only tracked block is looked up from the removed block, to keep walking back
+	var parent *BlockPointer
+	if len(s.blocks) == 1 && tip.Number > s.cfg.From {
+		header, err := s.client.pool.HeaderByHash(ctx, tip.Hash)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get header of removed block(=%d)", tip.Number)
+		}
+		parent = &BlockPointer{Number: tip.Number - 1, Hash: header.ParentHash}
+	}
+
+	s.client.logger.Info().Msgf("reorg detected, remove block(number=%d, hash=%s)", tip.Number, tip.Hash)
+
+	ev := BlockEvent{Type: Removed, Block: tip}
+	logs, err := s.logs(ctx, tip.Hash)
+	if err != nil {
+		// the node might have dropped the logs of the non canonical block
+		s.client.logger.Debug().Err(err).Msgf("failed to get logs of removed block(=%d)", tip.Number)
+	}
+	for i := range logs {
+		logs[i].Removed = true
+	}
+	ev.Logs = logs
+
+	if err = handler(ev); err != nil {
+		return wrapHandlerError(err, ev)
+	}
+
+	s.blocks = s.blocks[:len(s.blocks)-1]
+	if parent != nil {
+		s.blocks = append(s.blocks, *parent)
+	}
+	s.removed++
+	return s.save()
+}
+
+func (s *BlockStream) logs(ctx context.Context, hash common.Hash) ([]types.Log, error) {
+	if s.cfg.Logs == nil {
+		return nil, nil
+	}
+
+	query := *s.cfg.Logs
+	query.FromBlock, query.ToBlock, query.BlockHash = nil, nil, &hash
+
+	logs, err := s.client.pool.FilterLogs(ctx, query)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get logs of block(=%s)", hash)
+	}
+	return logs, nil
+}
+
+func (s *BlockStream) save() error {
+	if s.cfg.Cursor == nil {
+		return nil
+	}
+	if err := s.cfg.Cursor.Save(s.blocks); err != nil {
+		return errors.Wrap(err, "failed to save cursor")
+	}
+	return nil
+}
+
+// handlerError stops the stream, while the other errors are retried
+type handlerError struct {
+	err error
+}
+
+func (e *handlerError) Error() string { return e.err.Error() }
+func (e *handlerError) Unwrap() error { return e.err }
+
+// retryableError is the error of the handler to be retried, such as the failure of the rpc
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string        { return e.err.Error() }
+func (e *retryableError) Unwrap() error        { return e.err }
+func (e *retryableError) Is(target error) bool { return target == ErrRetry }
+
+// Retryable wraps the error of the handler, so that the stream redelivers the event instead of stopping
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err}
+}
+
+func wrapHandlerError(err error, ev BlockEvent) error {
+	err = errors.Wrapf(err, "failed to handle %s block(=%d)", ev.Type, ev.Block.Number)
+
+	if errors.Is(err, ErrRetry) {
+		return err
+	}
+	return &handlerError{err}
+}
+
+// FileCursor saves the tracked blocks in the json file
+type FileCursor struct {
+	path string
+}
+
+func NewFileCursor(path string) *FileCursor {
+	return &FileCursor{path: path}
+}
+
+func (c *FileCursor) Load() ([]BlockPointer, error) {
+	b, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to read cursor(=%s)", c.path)
+	}
+
+	var blocks []BlockPointer
+	if err = json.Unmarshal(b, &blocks); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse cursor(=%s)", c.path)
+	}
+	return blocks, nil
+}
+
+func (c *FileCursor) Save(blocks []BlockPointer) error {
+	b, err := json.Marshal(blocks)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal cursor")
+	}
+
+	// write to the temp file then rename, not to leave the broken file
+	tmp := c.path + ".tmp"
+	if err = os.WriteFile(tmp, b, 0o644); err != nil {
+		return errors.Wrapf(err, "failed to write cursor(=%s)", tmp)
+	}
+	if err = os.Rename(tmp, c.path); err != nil {
+		return errors.Wrapf(err, "failed to save cursor(=%s)", c.path)
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChain serves the blocks of the canonical chain, and a log per block unless the logs are given
+type fakeChain struct {
+	sync.Mutex
+	headers []*types.Header
+	blocks  map[common.Hash]*types.Block
+	txs     map[uint64][]*types.Transaction
+	logs    map[uint64][]*types.Log
+}
+
+func newFakeChain() *fakeChain {
+	return &fakeChain{
+		blocks: make(map[common.Hash]*types.Block),
+		txs:    make(map[uint64][]*types.Transaction),
+		logs:   make(map[uint64][]*types.Log),
+	}
+}
+
+func (c *fakeChain) fork(from, to uint64, extra byte) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.headers = c.headers[:from]
+	for n := from; n <= to; n++ {
+		h := &types.Header{Number: new(big.Int).SetUint64(n), Difficulty: big.NewInt(0), Time: uint64(time.Now().Unix()), Extra: []byte{extra}, UncleHash: types.EmptyUncleHash}
+		if n > 0 {
+			h.ParentHash = c.headers[n-1].Hash()
+		}
+		h.TxHash = types.EmptyRootHash
+		if len(c.txs[n]) > 0 {
+			// not the root of the txs, but enough for ethclient
+			h.TxHash = common.Hash{extra}
+		}
+		block := types.NewBlockWithHeader(h).WithBody(c.txs[n], nil)
+		c.blocks[block.Hash()] = block
+		c.headers = append(c.headers, block.Header())
+	}
+}
+
+func (c *fakeChain) serve(f *fakeNode) {
+	f.handle("eth_getBlockByNumber", func(params []json.RawMessage) (interface{}, error) {
+		c.Lock()
+		defer c.Unlock()
+
+		var tag string
+		if json.Unmarshal(params[0], &tag) == nil && tag == "latest" {
+			return c.headers[len(c.headers)-1], nil
+		}
+		var n hexutil.Uint64
+		_ = json.Unmarshal(params[0], &n)
+		if int(n) >= len(c.headers) {
+			return nil, nil
+		}
+		return c.headers[n], nil
+	})
+	f.handle("eth_getBlockByHash", func(params []json.RawMessage) (interface{}, error) {
+		c.Lock()
+		defer c.Unlock()
+
+		var hash common.Hash
+		_ = json.Unmarshal(params[0], &hash)
+		block, ok := c.blocks[hash]
+		if !ok {
+			return nil, nil
+		}
+
+		var fields map[string]interface{}
+		b, _ := json.Marshal(block.Header())
+		_ = json.Unmarshal(b, &fields)
+		fields["transactions"] = block.Transactions()
+		fields["uncles"] = []common.Hash{}
+		return fields, nil
+	})
+	f.handle("eth_getLogs", func(params []json.RawMessage) (interface{}, error) {
+		c.Lock()
+		defer c.Unlock()
+
+		var q struct {
+			BlockHash common.Hash `json:"blockHash"`
+		}
+		_ = json.Unmarshal(params[0], &q)
+
+		block, ok := c.blocks[q.BlockHash]
+		if !ok {
+			return []*types.Log{}, nil
+		}
+		logs, ok := c.logs[block.NumberU64()]
+		if !ok {
+			return []*types.Log{{BlockHash: q.BlockHash, Topics: []common.Hash{{0x01}}}}, nil
+		}
+		for _, l := range logs {
+			l.BlockHash = q.BlockHash
+		}
+		return logs, nil
+	})
+}
+
+func TestBlockStream(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		f      = newFakeNode(1010, 10)
+		chain  = newFakeChain()
+		cursor = NewFileCursor(filepath.Join(t.TempDir(), "cursor.json"))
+	)
+	defer f.Close()
+
+	chain.fork(0, 5, 0xa)
+	chain.serve(f)
+
+	c, err := NewClient(ctx, f.URL, nil, WithHeadPollInterval(10))
+	require.NoError(t, err)
+
+	stream := func(until BlockPointer) []BlockEvent {
+		s, err := c.NewBlockStream(BlockStreamConfig{From: 1, Confirmations: 1, Logs: &ethereum.FilterQuery{}, Cursor: cursor})
+		require.NoError(t, err)
+
+		var (
+			events      []BlockEvent
+			ctx, cancel = context.WithTimeout(ctx, 3*time.Second)
+		)
+		defer cancel()
+
+		err = s.Run(ctx, func(ev BlockEvent) error {
+			events = append(events, ev)
+			if ev.Type == Added && ev.Block == until {
+				cancel()
+			}
+			return nil
+		})
+		require.ErrorIs(t, err, context.Canceled)
+		return events
+	}
+
+	pointer := func(n uint64) BlockPointer {
+		chain.Lock()
+		defer chain.Unlock()
+		return BlockPointer{Number: n, Hash: chain.headers[n].Hash()}
+	}
+
+	// the head 5 is not confirmed
+	events := stream(pointer(4))
+	require.Len(t, events, 4)
+	for i, ev := range events {
+		require.Equal(t, Added, ev.Type)
+		require.Equal(t, pointer(uint64(i+1)), ev.Block)
+		require.Len(t, ev.Logs, 1)
+	}
+
+	removed := []BlockPointer{pointer(4), pointer(3)}
+	chain.fork(3, 7, 0xb)
+
+	// resumes from the cursor, and removes the reorged blocks
+	events = stream(pointer(6))
+	require.Len(t, events, 6)
+	require.Equal(t, Removed, events[0].Type)
+	require.Equal(t, removed[0], events[0].Block)
+	require.True(t, events[0].Logs[0].Removed)
+	require.Equal(t, Removed, events[1].Type)
+	require.Equal(t, removed[1], events[1].Block)
+	for i, ev := range events[2:] {
+		require.Equal(t, Added, ev.Type)
+		require.Equal(t, pointer(uint64(i+3)), ev.Block)
+	}
+}
+
+func TestBlockStreamRetry(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		f     = newFakeNode(1010, 10)
+		chain = newFakeChain()
+		fail  = errors.New("fail")
+	)
+	defer f.Close()
+
+	chain.fork(0, 3, 0xa)
+	chain.serve(f)
+
+	c, err := NewClient(ctx, f.URL, nil, WithHeadPollInterval(10))
+	require.NoError(t, err)
+
+	s, err := c.NewBlockStream(BlockStreamConfig{From: 1, Confirmations: 1})
+	require.NoError(t, err)
+
+	// the retryable error redelivers the event, and the other errors stop the stream
+	var delivered []uint64
+	err = s.Run(ctx, func(ev BlockEvent) error {
+		delivered = append(delivered, ev.Block.Number)
+		switch len(delivered) {
+		case 1:
+			return Retryable(fail)
+		case 2:
+			return errors.Wrap(ErrRetry, "not ready")
+		case 3:
+			return nil
+		}
+		return fail
+	})
+	require.ErrorIs(t, err, fail)
+	require.NotErrorIs(t, err, ErrRetry)
+	require.Equal(t, []uint64{1, 1, 1, 2}, delivered)
+}
+
+func TestBlockStreamShallowReorg(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		f     = newFakeNode(1010, 10)
+		chain = newFakeChain()
+	)
+	defer f.Close()
+
+	chain.fork(0, 3, 0xa)
+	chain.serve(f)
+
+	c, err := NewClient(ctx, f.URL, nil, WithHeadPollInterval(10))
+	require.NoError(t, err)
+
+	pointer := func(n uint64) BlockPointer {
+		chain.Lock()
+		defer chain.Unlock()
+		return BlockPointer{Number: n, Hash: chain.headers[n].Hash()}
+	}
+
+	stream := func(s *BlockStream, until BlockPointer) ([]BlockEvent, error) {
+		var (
+			events      []BlockEvent
+			ctx, cancel = context.WithTimeout(ctx, 3*time.Second)
+		)
+		defer cancel()
+
+		err := s.Run(ctx, func(ev BlockEvent) error {
+			events = append(events, ev)
+			if ev.Type == Added && ev.Block == until {
+				cancel()
+			}
+			return nil
+		})
+		return events, err
+	}
+
+	// the 1-block reorg right after the start
+	s, err := c.NewBlockStream(BlockStreamConfig{From: 3})
+	require.NoError(t, err)
+	events, err := stream(s, pointer(3))
+	require.ErrorIs(t, err, context.Canceled)
+	require.Len(t, events, 1)
+
+	old := []BlockPointer{pointer(3), pointer(2)}
+	chain.fork(3, 4, 0xb)
+
+	events, err = stream(s, pointer(4))
+	require.ErrorIs(t, err, context.Canceled)
+	require.Len(t, events, 3)
+	require.Equal(t, BlockEvent{Type: Removed, Block: old[0]}, events[0])
+	require.Equal(t, pointer(3), events[1].Block)
+	require.Equal(t, pointer(4), events[2].Block)
+
+	// the 1-entry cursor walks back to the parents of the removed blocks
+	cursor := NewFileCursor(filepath.Join(t.TempDir(), "cursor.json"))
+	require.NoError(t, cursor.Save([]BlockPointer{pointer(4)}))
+	chain.fork(2, 5, 0xc)
+
+	s, err = c.NewBlockStream(BlockStreamConfig{From: 1, Cursor: cursor})
+	require.NoError(t, err)
+	events, err = stream(s, pointer(5))
+	require.ErrorIs(t, err, context.Canceled)
+	require.Len(t, events, 7)
+	for i, n := range []uint64{4, 3, 2} {
+		require.Equal(t, Removed, events[i].Type)
+		require.Equal(t, n, events[i].Block.Number)
+	}
+	require.Equal(t, old[1], events[2].Block)
+	for i, ev := range events[3:] {
+		require.Equal(t, Added, ev.Type)
+		require.Equal(t, pointer(uint64(i+2)), ev.Block)
+	}
+
+	// the reorg deeper than the max reorg depth stops the stream
+	chain.fork(3, 6, 0xd)
+	s, err = c.NewBlockStream(BlockStreamConfig{From: 1, Cursor: cursor, MaxReorgDepth: 2})
+	require.NoError(t, err)
+	events, err = stream(s, pointer(6))
+	require.ErrorIs(t, err, ErrReorgTooDeep)
+	require.Len(t, events, 2)
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+var callTracer = map[string]interface{}{"tracer": "callTracer"}
+
+// CallFrame is the call tree of the callTracer
+type CallFrame struct {
+	Type         string // CALL, STATICCALL, DELEGATECALL, CALLCODE, CREATE, CREATE2 or SELFDESTRUCT
+	From         common.Address
+	To           common.Address
+	Value        *big.Int
+	Gas          uint64
+	GasUsed      uint64
+	Input        []byte
+	Output       []byte
+	Error        string
+	RevertReason string
+	Calls        []*CallFrame
+}
+
+// InternalTransfer is the native value moved by the call frame
+type InternalTransfer struct {
+	Type  string
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+	Depth int // 0 is the top level call of the tx
+}
+
+func (f *CallFrame) UnmarshalJSON(input []byte) error {
+	var frame struct {
+		Type         string         `json:"type"`
+		From         common.Address `json:"from"`
+		To           common.Address `json:"to"`
+		Value        *hexutil.Big   `json:"value"`
+		Gas          hexutil.Uint64 `json:"gas"`
+		GasUsed      hexutil.Uint64 `json:"gasUsed"`
+		Input        hexutil.Bytes  `json:"input"`
+		Output       hexutil.Bytes  `json:"output"`
+		Error        string         `json:"error"`
+		RevertReason string         `json:"revertReason"`
+		Calls        []*CallFrame   `json:"calls"`
+	}
+	if err := json.Unmarshal(input, &frame); err != nil {
+		return err
+	}
+
+	*f = CallFrame{
+		Type:         strings.ToUpper(frame.Type),
+		From:         frame.From,
+		To:           frame.To,
+		Value:        new(big.Int),
+		Gas:          uint64(frame.Gas),
+		GasUsed:      uint64(frame.GasUsed),
+		Input:        frame.Input,
+		Output:       frame.Output,
+		Error:        frame.Error,
+		RevertReason: frame.RevertReason,
+		Calls:        frame.Calls,
+	}
+	if frame.Value != nil {
+		f.Value = frame.Value.ToInt()
+	}
+	return nil
+}
+
+func (f *CallFrame) Reverted() bool {
+	return f.Error != ""
+}
+
+// Walk visits the frames in the depth first order
+func (f *CallFrame) Walk(fn func(frame *CallFrame, depth int)) {
+	f.walk(fn, 0)
+}
+
+func (f *CallFrame) walk(fn func(frame *CallFrame, depth int), depth int) {
+	fn(f, depth)
+	for _, call := range f.Calls {
+		call.walk(fn, depth+1)
+	}
+}
+
+// ValueTransfers returns the native value actually moved, excluding the frames reverted by themselves or by the callers.
+// DELEGATECALL and STATICCALL never move the value.
+func (f *CallFrame) ValueTransfers() []InternalTransfer {
+	var transfers []InternalTransfer
+	f.valueTransfers(&transfers, 0)
+	return transfers
+}
+
+func (f *CallFrame) valueTransfers(transfers *[]InternalTransfer, depth int) {
+	if f.Reverted() {
+		return
+	}
+
+	if f.Value.Sign() > 0 && f.Type != "DELEGATECALL" && f.Type != "STATICCALL" {
+		*transfers = append(*transfers, InternalTransfer{
+			Type:  f.Type,
+			From:  f.From,
+			To:    f.To,
+			Value: f.Value,
+			Depth: depth,
+		})
+	}
+
+	for _, call := range f.Calls {
+		call.valueTransfers(transfers, depth+1)
+	}
+}
+
+// TraceTransaction traces the tx by debug_traceTransaction with the callTracer,
+// which requires the node to enable the debug namespace
+func (c *Client) TraceTransaction(ctx context.Context, hash common.Hash) (*CallFrame, error) {
+	var frame CallFrame
+	if err := c.pool.CallContext(ctx, &frame, "debug_traceTransaction", hash, callTracer); err != nil {
+		return nil, errors.Wrapf(err, "failed to trace tx(=%s)", hash)
+	}
+	return &frame, nil
+}
+
+// TraceBlock traces all txs of the block in the order of the txs
+func (c *Client) TraceBlock(ctx context.Context, hash common.Hash) ([]*CallFrame, error) {
+	var results []struct {
+		Result *CallFrame `json:"result"`
+		Error  string     `json:"error"`
+	}
+	if err := c.pool.CallContext(ctx, &results, "debug_traceBlockByHash", hash, callTracer); err != nil {
+		return nil, errors.Wrapf(err, "failed to trace block(=%s)", hash)
+	}
+
+	frames := make([]*CallFrame, len(results))
+	for i := range results {
+		if results[i].Error != "" {
+			return nil, errors.Errorf("failed to trace tx[%d] of block(=%s): %s", i, hash, results[i].Error)
+		}
+		frames[i] = results[i].Result
+	}
+	return frames, nil
+}
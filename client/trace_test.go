@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceTransaction(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		f      = newFakeNode(1010, 10)
+		hash   = common.HexToHash("0x01")
+		wallet = common.HexToAddress("0xe1245045b2e1d3645f2d9c1bde93e9dcf9a8901f")
+		payee  = common.HexToAddress("0x621d7e7aed9ad05ac08519da02bcb7aab8660f0d")
+	)
+	defer f.Close()
+
+	// captured by debug_traceTransaction of geth v1.14.12 on a dev chain: a proxy wallet delegates to
+	// its logic, which recovers the signer, pays the payee and calls a contract reverting with a refund
+	fixture, err := os.ReadFile("testdata/trace_transaction.json")
+	require.NoError(t, err)
+
+	configs := make(chan json.RawMessage, 1)
+	f.handle("debug_traceTransaction", func(params []json.RawMessage) (interface{}, error) {
+		configs <- params[1]
+		return json.RawMessage(fixture), nil
+	})
+
+	c, err := NewClient(ctx, f.URL, nil)
+	require.NoError(t, err)
+
+	frame, err := c.TraceTransaction(ctx, hash)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"tracer":"callTracer"}`, string(<-configs))
+	require.Equal(t, "CALL", frame.Type)
+	require.Equal(t, uint64(0x13802), frame.GasUsed)
+	require.False(t, frame.Reverted())
+
+	var (
+		frames   int
+		reverted []*CallFrame
+	)
+	frame.Walk(func(f *CallFrame, depth int) {
+		frames++
+		if f.Reverted() {
+			reverted = append(reverted, f)
+		}
+	})
+	require.Equal(t, 6, frames)
+	require.Len(t, reverted, 1)
+	require.Equal(t, "not accepted", reverted[0].RevertReason)
+
+	// the value of the reverted frame and its children is not transferred
+	transfers := frame.ValueTransfers()
+	require.Len(t, transfers, 1)
+	require.Equal(t, InternalTransfer{
+		Type:  "CALL",
+		From:  wallet,
+		To:    payee,
+		Value: big.NewInt(1e18),
+		Depth: 2,
+	}, transfers[0])
+}
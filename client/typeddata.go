@@ -0,0 +1,116 @@
+package client
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/pkg/errors"
+)
+
+const (
+	EIP712DomainType = "EIP712Domain"
+)
+
+// NewTypedDataDomain builds the domain, where the zero values are omitted from the domain separator
+func NewTypedDataDomain(name, version string, chainID *big.Int, verifyingContract common.Address) apitypes.TypedDataDomain {
+	domain := apitypes.TypedDataDomain{
+		Name:    name,
+		Version: version,
+	}
+	if chainID != nil {
+		domain.ChainId = (*math.HexOrDecimal256)(new(big.Int).Set(chainID))
+	}
+	if verifyingContract != (common.Address{}) {
+		domain.VerifyingContract = verifyingContract.Hex()
+	}
+	return domain
+}
+
+// TypedDataDomain builds the domain on the chain of the client
+func (c *Client) TypedDataDomain(name, version string, verifyingContract common.Address) apitypes.TypedDataDomain {
+	return NewTypedDataDomain(name, version, c.chainID, verifyingContract)
+}
+
+// NewTypedData builds the typed data, adding the EIP712Domain type by the fields set in the domain
+func NewTypedData(domain apitypes.TypedDataDomain, primaryType string, types apitypes.Types, message apitypes.TypedDataMessage) apitypes.TypedData {
+	all := apitypes.Types{EIP712DomainType: domainType(domain)}
+	for name, fields := range types {
+		all[name] = fields
+	}
+
+	return apitypes.TypedData{
+		Types:       all,
+		PrimaryType: primaryType,
+		Domain:      domain,
+		Message:     message,
+	}
+}
+
+// the order of the fields is defined by the eip
+func domainType(domain apitypes.TypedDataDomain) []apitypes.Type {
+	var fields []apitypes.Type
+	if domain.Name != "" {
+		fields = append(fields, apitypes.Type{Name: "name", Type: "string"})
+	}
+	if domain.Version != "" {
+		fields = append(fields, apitypes.Type{Name: "version", Type: "string"})
+	}
+	if domain.ChainId != nil {
+		fields = append(fields, apitypes.Type{Name: "chainId", Type: "uint256"})
+	}
+	if domain.VerifyingContract != "" {
+		fields = append(fields, apitypes.Type{Name: "verifyingContract", Type: "address"})
+	}
+	if domain.Salt != "" {
+		fields = append(fields, apitypes.Type{Name: "salt", Type: "bytes32"})
+	}
+	return fields
+}
+
+// TypedDataHash returns the digest to be signed, keccak256("\x19\x01" ‖ domainSeparator ‖ hashStruct(message))
+func TypedDataHash(typedData apitypes.TypedData) (common.Hash, error) {
+	if _, ok := typedData.Types[EIP712DomainType]; !ok {
+		typedData = NewTypedData(typedData.Domain, typedData.PrimaryType, typedData.Types, typedData.Message)
+	}
+
+	domainSeparator, err := typedData.HashStruct(EIP712DomainType, typedData.Domain.Map())
+	if err != nil {
+		return common.Hash{}, errors.Wrap(err, "failed to hash domain")
+	}
+
+	messageHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		return common.Hash{}, errors.Wrapf(err, "failed to hash %s", typedData.PrimaryType)
+	}
+
+	return crypto.Keccak256Hash([]byte("\x19\x01"), domainSeparator, messageHash), nil
+}
+
+// SignTypedData signs the typed data by the signer, and returns the signature with V of 27 or 28
+func SignTypedData(signer Signer, typedData apitypes.TypedData) ([]byte, error) {
+	hash, err := TypedDataHash(typedData)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := signer.SignHash(hash)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign typed data")
+	}
+	if sig[crypto.RecoveryIDOffset] < 27 {
+		sig[crypto.RecoveryIDOffset] += 27
+	}
+	return sig, nil
+}
+
+// RecoverTypedDataSigner recovers the address signed the typed data
+func RecoverTypedDataSigner(typedData apitypes.TypedData, sig []byte) (common.Address, error) {
+	hash, err := TypedDataHash(typedData)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return recoverSigner(hash, sig)
+}
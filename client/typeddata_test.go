@@ -0,0 +1,69 @@
+package client
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/require"
+)
+
+// the Mail example of the eip-712 reference implementation
+func mailTypedData() apitypes.TypedData {
+	domain := NewTypedDataDomain("Ether Mail", "1", big.NewInt(1), common.HexToAddress("0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"))
+	return NewTypedData(domain, "Mail", apitypes.Types{
+		"Person": {
+			{Name: "name", Type: "string"},
+			{Name: "wallet", Type: "address"},
+		},
+		"Mail": {
+			{Name: "from", Type: "Person"},
+			{Name: "to", Type: "Person"},
+			{Name: "contents", Type: "string"},
+		},
+	}, apitypes.TypedDataMessage{
+		"from": map[string]interface{}{
+			"name":   "Cow",
+			"wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826",
+		},
+		"to": map[string]interface{}{
+			"name":   "Bob",
+			"wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB",
+		},
+		"contents": "Hello, Bob!",
+	})
+}
+
+func TestTypedData(t *testing.T) {
+	typedData := mailTypedData()
+
+	hash, err := TypedDataHash(typedData)
+	require.NoError(t, err)
+	require.Equal(t, "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2", hash.Hex())
+
+	signer, err := NewKeySigner(common.Bytes2Hex(crypto.Keccak256([]byte("cow"))))
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"), signer.Address())
+
+	sig, err := SignTypedData(signer, typedData)
+	require.NoError(t, err)
+	require.Equal(t, "0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d", hexutil.Encode(sig[:32]))
+	require.Equal(t, "0x07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562", hexutil.Encode(sig[32:64]))
+	require.Equal(t, byte(28), sig[64])
+
+	addr, err := RecoverTypedDataSigner(typedData, sig)
+	require.NoError(t, err)
+	require.Equal(t, signer.Address(), addr)
+
+	// the tampered message is recovered to the other address
+	typedData.Message["contents"] = "Hello, Alice!"
+	addr, err = RecoverTypedDataSigner(typedData, sig)
+	require.NoError(t, err)
+	require.NotEqual(t, signer.Address(), addr)
+
+	_, err = RecoverTypedDataSigner(typedData, sig[:64])
+	require.ErrorIs(t, err, ErrInvalidSignature)
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/pkg/errors"
@@ -43,3 +44,32 @@ func ToWei(iamount interface{}, decimals int) *big.Int {
 
 	return wei
 }
+
+// MessageHash returns the EIP-191 personal_sign digest, keccak256("\x19Ethereum Signed Message:\n" ‖ len(msg) ‖ msg)
+func MessageHash(msg []byte) common.Hash {
+	return common.BytesToHash(accounts.TextHash(msg))
+}
+
+// SignMessage signs the message as personal_sign, and returns the signature with V of 27 or 28
+func SignMessage(signer Signer, msg []byte) ([]byte, error) {
+	sig, err := signer.SignHash(MessageHash(msg))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign message")
+	}
+	if sig[crypto.RecoveryIDOffset] < 27 {
+		sig[crypto.RecoveryIDOffset] += 27
+	}
+	return sig, nil
+}
+
+// RecoverAddress recovers the address signed the message by personal_sign, accepting V of either 0/1 or 27/28
+func RecoverAddress(msg, sig []byte) (common.Address, error) {
+	return recoverSigner(MessageHash(msg), sig)
+}
+
+// VerifyMessage reports whether the message is signed by the address.
+// The contract accounts are not supported, use Client.VerifySignature instead.
+func VerifyMessage(address common.Address, msg, sig []byte) bool {
+	addr, err := RecoverAddress(msg, sig)
+	return err == nil && addr == address
+}
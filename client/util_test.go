@@ -0,0 +1,38 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignMessage(t *testing.T) {
+	msg := []byte("Hello World")
+	require.Equal(t, "0xa1de988600a42c4b4ab089b619297c17d53cffae5d5120d82d8a92d0bb3b78f2", MessageHash(msg).Hex())
+
+	signer, err := NewKeySigner(TestPrivKey)
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress(TestAccount), signer.Address())
+
+	sig, err := SignMessage(signer, msg)
+	require.NoError(t, err)
+	require.Contains(t, []byte{27, 28}, sig[crypto.RecoveryIDOffset])
+
+	addr, err := RecoverAddress(msg, sig)
+	require.NoError(t, err)
+	require.Equal(t, signer.Address(), addr)
+	require.True(t, VerifyMessage(signer.Address(), msg, sig))
+
+	// v in 0/1 form
+	raw := common.CopyBytes(sig)
+	raw[crypto.RecoveryIDOffset] -= 27
+	require.True(t, VerifyMessage(signer.Address(), msg, raw))
+
+	require.False(t, VerifyMessage(signer.Address(), []byte("Hello World!"), sig))
+	require.False(t, VerifyMessage(signer.Address(), msg, sig[:64]))
+
+	_, err = RecoverAddress(msg, sig[:64])
+	require.ErrorIs(t, err, ErrInvalidSignature)
+}
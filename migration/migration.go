@@ -0,0 +1,221 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tak1827/eth-extended-client/client"
+)
+
+var (
+	ErrDuplicatedStep = errors.New("duplicated step name")
+	ErrUnknownRef     = errors.New("referred step is not applied")
+	ErrTxFailed       = errors.New("tx failed")
+)
+
+// Ref is the arg replaced by the address deployed by the step of the name
+type Ref string
+
+// Step is the unit of the migration, which is applied only once per chain.
+// Run returns the record to be saved in the registry, and the Name and AppliedAt are filled by the migrator.
+type Step struct {
+	Name string
+	Run  func(ctx context.Context, m *Migrator) (*Record, error)
+}
+
+// Migrator applies the steps in order, skipping the steps recorded in the registry
+type Migrator struct {
+	client   *client.Client
+	priv     string
+	chainID  string
+	registry *Registry
+	steps    []Step
+}
+
+func NewMigrator(c *client.Client, priv string, registry *Registry, steps ...Step) (*Migrator, error) {
+	names := make(map[string]struct{}, len(steps))
+	for _, s := range steps {
+		if _, ok := names[s.Name]; ok {
+			return nil, errors.Wrapf(ErrDuplicatedStep, "name=%s", s.Name)
+		}
+		names[s.Name] = struct{}{}
+	}
+
+	return &Migrator{
+		client:   c,
+		priv:     priv,
+		chainID:  c.ChainID().String(),
+		registry: registry,
+		steps:    steps,
+	}, nil
+}
+
+func (m *Migrator) Client() *client.Client {
+	return m.client
+}
+
+func (m *Migrator) ChainID() string {
+	return m.chainID
+}
+
+// Address returns the address deployed by the applied step
+func (m *Migrator) Address(name string) (common.Address, error) {
+	addr, ok := m.registry.Address(m.chainID, name)
+	if !ok {
+		return common.Address{}, errors.Wrapf(ErrUnknownRef, "name=%s", name)
+	}
+	return addr, nil
+}
+
+// Run applies the pending steps, and returns the records of the steps applied by this run
+func (m *Migrator) Run(ctx context.Context) ([]Record, error) {
+	var applied []Record
+
+	for _, s := range m.steps {
+		if _, ok := m.registry.Get(m.chainID, s.Name); ok {
+			continue
+		}
+
+		record, err := s.Run(ctx, m)
+		if err != nil {
+			return applied, errors.Wrapf(err, "failed to apply step(=%s)", s.Name)
+		}
+		if record == nil {
+			record = &Record{}
+		}
+		record.Name = s.Name
+		record.AppliedAt = time.Now().UTC()
+
+		if err = m.registry.add(m.chainID, *record); err != nil {
+			return applied, err
+		}
+		applied = append(applied, *record)
+	}
+
+	return applied, nil
+}
+
+// resolve replaces the Refs in the args with the deployed addresses
+func (m *Migrator) resolve(args []interface{}) ([]interface{}, error) {
+	resolved := make([]interface{}, len(args))
+	for i := range args {
+		ref, ok := args[i].(Ref)
+		if !ok {
+			resolved[i] = args[i]
+			continue
+		}
+		addr, err := m.Address(string(ref))
+		if err != nil {
+			return nil, err
+		}
+		resolved[i] = addr
+	}
+	return resolved, nil
+}
+
+// Deploy is the step to deploy the contract
+func Deploy(name string, parsed *abi.ABI, bytecode []byte, args ...interface{}) Step {
+	return Step{Name: name, Run: func(ctx context.Context, m *Migrator) (*Record, error) {
+		resolved, err := m.resolve(args)
+		if err != nil {
+			return nil, err
+		}
+
+		addr, receipt, err := m.client.Deploy(ctx, m.priv, parsed, bytecode, resolved...)
+		if err != nil {
+			return nil, err
+		}
+
+		return deployRecord(addr, receipt, parsed, resolved)
+	}}
+}
+
+// DeployCreate2 is the step to deploy the contract at the deterministic address.
+// The contract deployed out of the migration is recorded without the tx hash.
+func DeployCreate2(name string, salt [32]byte, parsed *abi.ABI, bytecode []byte, args ...interface{}) Step {
+	return Step{Name: name, Run: func(ctx context.Context, m *Migrator) (*Record, error) {
+		resolved, err := m.resolve(args)
+		if err != nil {
+			return nil, err
+		}
+
+		addr, receipt, err := m.client.DeployCreate2(ctx, m.priv, salt, parsed, bytecode, resolved...)
+		if err != nil {
+			return nil, err
+		}
+
+		return deployRecord(addr, receipt, parsed, resolved)
+	}}
+}
+
+// Call is the step to send the tx calling the method of the contract deployed by the step of the target
+func Call(name string, target Ref, parsed *abi.ABI, method string, args ...interface{}) Step {
+	return Step{Name: name, Run: func(ctx context.Context, m *Migrator) (*Record, error) {
+		to, err := m.Address(string(target))
+		if err != nil {
+			return nil, err
+		}
+
+		resolved, err := m.resolve(args)
+		if err != nil {
+			return nil, err
+		}
+
+		input, err := parsed.Pack(method, resolved...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to pack %s", method)
+		}
+
+		hash, err := m.client.SyncSend(ctx, m.priv, &to, nil, input, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to send %s", method)
+		}
+
+		receipt, err := m.client.Receipt(ctx, hash)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get receipt of %s", hash)
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			return nil, errors.Wrapf(ErrTxFailed, "tx=%s", hash)
+		}
+
+		return &Record{Address: &to, TxHash: &receipt.TxHash, Args: formatArgs(resolved)}, nil
+	}}
+}
+
+func deployRecord(addr common.Address, receipt *types.Receipt, parsed *abi.ABI, args []interface{}) (*Record, error) {
+	record := &Record{Address: &addr, Args: formatArgs(args)}
+	if receipt != nil {
+		record.TxHash = &receipt.TxHash
+	}
+
+	if parsed != nil {
+		data, err := parsed.Pack("", args...)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to pack constructor args")
+		}
+		record.ArgsData = data
+	}
+
+	return record, nil
+}
+
+func formatArgs(args []interface{}) []string {
+	formatted := make([]string, len(args))
+	for i := range args {
+		switch v := args[i].(type) {
+		case common.Address:
+			formatted[i] = v.Hex()
+		case []byte:
+			formatted[i] = fmt.Sprintf("0x%x", v)
+		default:
+			formatted[i] = fmt.Sprintf("%v", v)
+		}
+	}
+	return formatted
+}
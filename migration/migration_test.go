@@ -0,0 +1,75 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigratorRun(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		path  = filepath.Join(t.TempDir(), "registry.json")
+		token = common.HexToAddress("0x01")
+		runs  = make(map[string]int)
+		fail  = true
+	)
+
+	deployToken := Step{Name: "token", Run: func(ctx context.Context, m *Migrator) (*Record, error) {
+		runs["token"]++
+		return &Record{Address: &token}, nil
+	}}
+	initToken := Step{Name: "init", Run: func(ctx context.Context, m *Migrator) (*Record, error) {
+		runs["init"]++
+		if fail {
+			return nil, errors.New("failed")
+		}
+		resolved, err := m.resolve([]interface{}{Ref("token"), 1})
+		require.NoError(t, err)
+		require.Equal(t, []interface{}{token, 1}, resolved)
+		return nil, nil
+	}}
+
+	newMigrator := func() *Migrator {
+		registry, err := LoadRegistry(path)
+		require.NoError(t, err)
+		return &Migrator{chainID: "1010", registry: registry, steps: []Step{deployToken, initToken}}
+	}
+
+	// the applied step is saved even if the later step fails
+	_, err := newMigrator().Run(ctx)
+	require.Error(t, err)
+
+	// the registry is readable by the others, as it might be shared
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+
+	fail = false
+	m := newMigrator()
+	applied, err := m.Run(ctx)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	require.Equal(t, "init", applied[0].Name)
+	require.Equal(t, map[string]int{"token": 1, "init": 2}, runs)
+
+	addr, err := m.Address("token")
+	require.NoError(t, err)
+	require.Equal(t, token, addr)
+
+	// nothing to apply on re-run
+	applied, err = newMigrator().Run(ctx)
+	require.NoError(t, err)
+	require.Empty(t, applied)
+
+	// the registry is per chain
+	other := newMigrator()
+	other.chainID = "1"
+	_, err = other.Address("token")
+	require.ErrorIs(t, err, ErrUnknownRef)
+}
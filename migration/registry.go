@@ -0,0 +1,99 @@
+package migration
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+// Record is the applied step
+type Record struct {
+	Name      string          `json:"name"`
+	Address   *common.Address `json:"address,omitempty"`
+	TxHash    *common.Hash    `json:"txHash,omitempty"`
+	Args      []string        `json:"args,omitempty"`
+	ArgsData  hexutil.Bytes   `json:"argsData,omitempty"` // abi encoded constructor args, such as to verify the source
+	AppliedAt time.Time       `json:"appliedAt"`
+}
+
+// Registry is the json file of the applied steps per chain id
+type Registry struct {
+	sync.Mutex
+	path   string
+	chains map[string][]Record
+}
+
+// LoadRegistry loads the registry file, and the missing file is the empty registry
+func LoadRegistry(path string) (*Registry, error) {
+	r := &Registry{path: path, chains: make(map[string][]Record)}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to read registry(=%s)", path)
+	}
+
+	if err = json.Unmarshal(b, &r.chains); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse registry(=%s)", path)
+	}
+	return r, nil
+}
+
+func (r *Registry) Get(chainID, name string) (Record, bool) {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, record := range r.chains[chainID] {
+		if record.Name == name {
+			return record, true
+		}
+	}
+	return Record{}, false
+}
+
+// Address returns the address of the contract deployed by the step
+func (r *Registry) Address(chainID, name string) (common.Address, bool) {
+	record, ok := r.Get(chainID, name)
+	if !ok || record.Address == nil {
+		return common.Address{}, false
+	}
+	return *record.Address, true
+}
+
+func (r *Registry) Records(chainID string) []Record {
+	r.Lock()
+	defer r.Unlock()
+	return append([]Record{}, r.chains[chainID]...)
+}
+
+// add records the step and saves the file, so that the progress survives the failure of the later steps
+func (r *Registry) add(chainID string, record Record) error {
+	r.Lock()
+	defer r.Unlock()
+
+	r.chains[chainID] = append(r.chains[chainID], record)
+	return r.save()
+}
+
+func (r *Registry) save() error {
+	b, err := json.MarshalIndent(r.chains, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal registry")
+	}
+
+	// write to the temp file then rename, not to leave the broken file
+	tmp := r.path + ".tmp"
+	if err = os.WriteFile(tmp, append(b, '\n'), 0o644); err != nil {
+		return errors.Wrapf(err, "failed to write registry(=%s)", tmp)
+	}
+	if err = os.Rename(tmp, r.path); err != nil {
+		return errors.Wrapf(err, "failed to save registry(=%s)", r.path)
+	}
+	return nil
+}
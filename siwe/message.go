@@ -0,0 +1,247 @@
+package siwe
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	Version = "1"
+
+	headerSuffix = " wants you to sign in with your Ethereum account:"
+	nonceChars   = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	nonceMinLen  = 8
+)
+
+// Message is the EIP-4361 message. The zero ExpirationTime and NotBefore are omitted.
+type Message struct {
+	Scheme         string // optional, such as https
+	Domain         string
+	Address        common.Address
+	Statement      string // optional, must not contain the newline
+	URI            string
+	Version        string
+	ChainID        uint64
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime time.Time
+	NotBefore      time.Time
+	RequestID      string
+	Resources      []string
+}
+
+// GenerateNonce returns the random alphanumeric nonce of the length, which is at least 8
+func GenerateNonce(length int) (string, error) {
+	if length < nonceMinLen {
+		length = nonceMinLen
+	}
+
+	max := big.NewInt(int64(len(nonceChars)))
+	b := make([]byte, length)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to generate nonce")
+		}
+		b[i] = nonceChars[n.Int64()]
+	}
+	return string(b), nil
+}
+
+// String formats the message to be signed by personal_sign
+func (m *Message) String() string {
+	var b strings.Builder
+
+	if m.Scheme != "" {
+		b.WriteString(m.Scheme + "://")
+	}
+	b.WriteString(m.Domain + headerSuffix + "\n")
+	b.WriteString(m.Address.Hex() + "\n\n")
+	if m.Statement != "" {
+		b.WriteString(m.Statement + "\n")
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "URI: %s\n", m.URI)
+	fmt.Fprintf(&b, "Version: %s\n", m.Version)
+	fmt.Fprintf(&b, "Chain ID: %d\n", m.ChainID)
+	fmt.Fprintf(&b, "Nonce: %s\n", m.Nonce)
+	fmt.Fprintf(&b, "Issued At: %s", m.IssuedAt.Format(time.RFC3339Nano))
+	if !m.ExpirationTime.IsZero() {
+		fmt.Fprintf(&b, "\nExpiration Time: %s", m.ExpirationTime.Format(time.RFC3339Nano))
+	}
+	if !m.NotBefore.IsZero() {
+		fmt.Fprintf(&b, "\nNot Before: %s", m.NotBefore.Format(time.RFC3339Nano))
+	}
+	if m.RequestID != "" {
+		fmt.Fprintf(&b, "\nRequest ID: %s", m.RequestID)
+	}
+	if len(m.Resources) > 0 {
+		b.WriteString("\nResources:")
+		for _, r := range m.Resources {
+			b.WriteString("\n- " + r)
+		}
+	}
+
+	return b.String()
+}
+
+// ParseMessage parses the EIP-4361 message, the statement is allowed to be absent without the extra empty line
+func ParseMessage(raw string) (*Message, error) {
+	var (
+		m = &Message{}
+		p = &parser{lines: strings.Split(raw, "\n")}
+	)
+
+	header := p.next()
+	if !strings.HasSuffix(header, headerSuffix) {
+		return nil, p.errorf("missing header")
+	}
+	m.Domain = strings.TrimSuffix(header, headerSuffix)
+	if i := strings.Index(m.Domain, "://"); i >= 0 {
+		m.Scheme, m.Domain = m.Domain[:i], m.Domain[i+3:]
+	}
+	if m.Domain == "" {
+		return nil, p.errorf("empty domain")
+	}
+
+	addr := p.next()
+	if !common.IsHexAddress(addr) || !strings.HasPrefix(addr, "0x") {
+		return nil, p.errorf("invalid address(=%s)", addr)
+	}
+	m.Address = common.HexToAddress(addr)
+	if addr != m.Address.Hex() {
+		return nil, p.errorf("address(=%s) is not checksummed", addr)
+	}
+
+	if p.next() != "" {
+		return nil, p.errorf("missing empty line after address")
+	}
+	if line := p.peek(); line != "" && !strings.HasPrefix(line, "URI: ") {
+		m.Statement = p.next()
+	}
+	if p.peek() == "" {
+		p.next()
+	}
+
+	var err error
+	if m.URI, err = p.field("URI", true); err != nil {
+		return nil, err
+	}
+	if m.Version, err = p.field("Version", true); err != nil {
+		return nil, err
+	}
+	if m.Version != Version {
+		return nil, p.errorf("unsupported version(=%s)", m.Version)
+	}
+
+	chainID, err := p.field("Chain ID", true)
+	if err != nil {
+		return nil, err
+	}
+	if m.ChainID, err = strconv.ParseUint(chainID, 10, 64); err != nil {
+		return nil, p.errorf("invalid chain id(=%s)", chainID)
+	}
+
+	if m.Nonce, err = p.field("Nonce", true); err != nil {
+		return nil, err
+	}
+	if !isNonce(m.Nonce) {
+		return nil, p.errorf("invalid nonce(=%s)", m.Nonce)
+	}
+
+	if m.IssuedAt, err = p.timeField("Issued At", true); err != nil {
+		return nil, err
+	}
+	if m.ExpirationTime, err = p.timeField("Expiration Time", false); err != nil {
+		return nil, err
+	}
+	if m.NotBefore, err = p.timeField("Not Before", false); err != nil {
+		return nil, err
+	}
+	if m.RequestID, err = p.field("Request ID", false); err != nil {
+		return nil, err
+	}
+
+	if p.peek() == "Resources:" {
+		p.next()
+		for strings.HasPrefix(p.peek(), "- ") {
+			m.Resources = append(m.Resources, strings.TrimPrefix(p.next(), "- "))
+		}
+	}
+
+	if !p.done() {
+		return nil, p.errorf("unexpected line(=%s)", p.peek())
+	}
+
+	return m, nil
+}
+
+func isNonce(nonce string) bool {
+	if len(nonce) < nonceMinLen {
+		return false
+	}
+	for _, r := range nonce {
+		if !strings.ContainsRune(nonceChars, r) {
+			return false
+		}
+	}
+	return true
+}
+
+type parser struct {
+	lines []string
+	pos   int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.lines)
+}
+
+func (p *parser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.lines[p.pos]
+}
+
+func (p *parser) next() string {
+	line := p.peek()
+	p.pos++
+	return line
+}
+
+func (p *parser) field(tag string, required bool) (string, error) {
+	prefix := tag + ": "
+	if !strings.HasPrefix(p.peek(), prefix) {
+		if required {
+			return "", p.errorf("missing %s", tag)
+		}
+		return "", nil
+	}
+	return strings.TrimPrefix(p.next(), prefix), nil
+}
+
+func (p *parser) timeField(tag string, required bool) (time.Time, error) {
+	v, err := p.field(tag, required)
+	if err != nil || v == "" {
+		return time.Time{}, err
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return time.Time{}, p.errorf("invalid %s(=%s)", tag, v)
+	}
+	return t, nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return errors.Wrapf(ErrInvalidMessage, "line %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
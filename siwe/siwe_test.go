@@ -0,0 +1,140 @@
+package siwe
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"github.com/tak1827/eth-extended-client/client"
+)
+
+const (
+	TestPrivKey  = "d1c71e71b06e248c8dbe94d49ef6d6b0d64f5d71b1e33a0f39e14dadb070304a"
+	TestPrivKey2 = "8179ce3d00ac1d1d1d38e4f038de00ccd0e0375517164ac5448e3acc847acb34"
+)
+
+// the example of the eip
+const exampleMessage = `service.invalid wants you to sign in with your Ethereum account:
+0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2
+
+I accept the ServiceOrg Terms of Service: https://service.invalid/tos
+
+URI: https://service.invalid/login
+Version: 1
+Chain ID: 1
+Nonce: 32891756
+Issued At: 2021-09-30T16:25:24Z
+Resources:
+- ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq/
+- https://example.com/my-web2-claim.json`
+
+func TestParseMessage(t *testing.T) {
+	m, err := ParseMessage(exampleMessage)
+	require.NoError(t, err)
+	require.Equal(t, "service.invalid", m.Domain)
+	require.Equal(t, common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"), m.Address)
+	require.Equal(t, "I accept the ServiceOrg Terms of Service: https://service.invalid/tos", m.Statement)
+	require.Equal(t, "https://service.invalid/login", m.URI)
+	require.Equal(t, uint64(1), m.ChainID)
+	require.Equal(t, "32891756", m.Nonce)
+	require.Equal(t, time.Date(2021, 9, 30, 16, 25, 24, 0, time.UTC), m.IssuedAt)
+	require.Len(t, m.Resources, 2)
+	require.Equal(t, exampleMessage, m.String())
+
+	// without the statement
+	m.Scheme, m.Statement, m.Resources = "https", "", nil
+	m.ExpirationTime = m.IssuedAt.Add(time.Hour)
+	m.RequestID = "request-1"
+	parsed, err := ParseMessage(m.String())
+	require.NoError(t, err)
+	require.Equal(t, m, parsed)
+
+	// the statement is absent without the extra empty line
+	parsed, err = ParseMessage(strings.Replace(m.String(), "\n\n\n", "\n\n", 1))
+	require.NoError(t, err)
+	require.Equal(t, m, parsed)
+
+	for _, raw := range []string{
+		strings.Replace(exampleMessage, "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2", 1),
+		strings.Replace(exampleMessage, "Nonce: 32891756\n", "", 1),
+		strings.Replace(exampleMessage, "Nonce: 32891756", "Nonce: 1234", 1),
+		strings.Replace(exampleMessage, "Version: 1", "Version: 2", 1),
+		strings.Replace(exampleMessage, " wants you", " want you", 1),
+		exampleMessage + "\nunknown",
+	} {
+		_, err = ParseMessage(raw)
+		require.ErrorIs(t, err, ErrInvalidMessage)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		now      = time.Now().UTC().Truncate(time.Second)
+		verifier = NewVerifier(nil)
+	)
+
+	signer, err := client.NewKeySigner(TestPrivKey)
+	require.NoError(t, err)
+	other, err := client.NewKeySigner(TestPrivKey2)
+	require.NoError(t, err)
+
+	nonce, err := GenerateNonce(16)
+	require.NoError(t, err)
+	require.Len(t, nonce, 16)
+
+	m := &Message{
+		Domain:         "example.com",
+		Address:        signer.Address(),
+		Statement:      "Sign in to Example",
+		URI:            "https://example.com/login",
+		Version:        Version,
+		ChainID:        1010,
+		Nonce:          nonce,
+		IssuedAt:       now,
+		ExpirationTime: now.Add(time.Minute),
+	}
+	raw := m.String()
+
+	sig, err := client.SignMessage(signer, []byte(raw))
+	require.NoError(t, err)
+
+	opts := VerifyOpts{Domain: "example.com", Nonce: nonce, ChainID: 1010}
+	verified, err := verifier.Verify(ctx, raw, sig, opts)
+	require.NoError(t, err)
+	require.Equal(t, m, verified)
+
+	// signed by the other account
+	otherSig, err := client.SignMessage(other, []byte(raw))
+	require.NoError(t, err)
+	_, err = verifier.Verify(ctx, raw, otherSig, opts)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	// the domain and the nonce are never skipped
+	_, err = verifier.Verify(ctx, raw, sig, VerifyOpts{Nonce: nonce})
+	require.ErrorIs(t, err, ErrMissingOpts)
+	_, err = verifier.Verify(ctx, raw, sig, VerifyOpts{Domain: "example.com"})
+	require.ErrorIs(t, err, ErrMissingOpts)
+
+	var verr *Error
+	_, err = verifier.Verify(ctx, raw, sig, VerifyOpts{Domain: "evil.com", Nonce: nonce})
+	require.ErrorIs(t, err, ErrDomainMismatch)
+	require.ErrorAs(t, err, &verr)
+	require.Equal(t, "evil.com", verr.Expected)
+	require.Equal(t, "example.com", verr.Got)
+
+	_, err = verifier.Verify(ctx, raw, sig, VerifyOpts{Domain: "example.com", Nonce: "replayed0"})
+	require.ErrorIs(t, err, ErrNonceMismatch)
+
+	_, err = verifier.Verify(ctx, raw, sig, VerifyOpts{Domain: "example.com", Nonce: nonce, ChainID: 1})
+	require.ErrorIs(t, err, ErrChainIDMismatch)
+
+	_, err = verifier.Verify(ctx, raw, sig, VerifyOpts{Domain: "example.com", Nonce: nonce, Time: now.Add(time.Hour)})
+	require.ErrorIs(t, err, ErrExpired)
+
+	m.NotBefore = now.Add(time.Second)
+	require.ErrorIs(t, m.Validate(VerifyOpts{Domain: "example.com", Nonce: nonce, Time: now}), ErrNotYetValid)
+}
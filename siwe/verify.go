@@ -0,0 +1,122 @@
+package siwe
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tak1827/eth-extended-client/client"
+)
+
+var (
+	ErrInvalidMessage   = errors.New("invalid siwe message")
+	ErrMissingOpts      = errors.New("missing verify opts")
+	ErrDomainMismatch   = errors.New("domain mismatch")
+	ErrNonceMismatch    = errors.New("nonce mismatch")
+	ErrChainIDMismatch  = errors.New("chain id mismatch")
+	ErrExpired          = errors.New("message is expired")
+	ErrNotYetValid      = errors.New("message is not yet valid")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Error is the failure of the validation, which matches the sentinel of the Kind by errors.Is
+type Error struct {
+	Kind     error
+	Expected string
+	Got      string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: expected %s, got %s", e.Kind, e.Expected, e.Got)
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+// VerifyOpts is the expectation of the message. The Domain and the Nonce issued by the server are required,
+// and the zero ChainID and Time default to the chain of the client and now.
+type VerifyOpts struct {
+	Domain  string
+	Nonce   string
+	ChainID uint64
+	Time    time.Time
+}
+
+// Verifier verifies the signed messages. The signature of the contract wallet is verified by EIP-1271,
+// which needs the client, otherwise only the signature of the externally owned account is accepted.
+type Verifier struct {
+	client *client.Client
+}
+
+func NewVerifier(c *client.Client) *Verifier {
+	return &Verifier{client: c}
+}
+
+// Verify parses the raw message, validates it by the opts, and verifies the signature of the address in the message
+func (v *Verifier) Verify(ctx context.Context, raw string, sig []byte, opts VerifyOpts) (*Message, error) {
+	m, err := ParseMessage(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.ChainID == 0 && v.client != nil {
+		opts.ChainID = v.client.ChainID().Uint64()
+	}
+	if err = m.Validate(opts); err != nil {
+		return nil, err
+	}
+
+	// the signature is verified against the raw message as signed, not the formatted one
+	if client.VerifyMessage(m.Address, []byte(raw), sig) {
+		return m, nil
+	}
+
+	if v.client == nil {
+		return nil, errors.Wrapf(ErrInvalidSignature, "not signed by %s", m.Address)
+	}
+
+	ok, err := v.client.VerifySignature(ctx, m.Address, client.MessageHash([]byte(raw)), sig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to verify signature")
+	}
+	if !ok {
+		return nil, errors.Wrapf(ErrInvalidSignature, "not signed by %s", m.Address)
+	}
+
+	return m, nil
+}
+
+// Validate checks the domain, nonce, chain id and the validity period of the message
+func (m *Message) Validate(opts VerifyOpts) error {
+	if opts.Domain == "" {
+		return errors.Wrap(ErrMissingOpts, "domain is required")
+	}
+	if opts.Nonce == "" {
+		return errors.Wrap(ErrMissingOpts, "nonce is required")
+	}
+
+	if m.Domain != opts.Domain {
+		return &Error{Kind: ErrDomainMismatch, Expected: opts.Domain, Got: m.Domain}
+	}
+	if m.Nonce != opts.Nonce {
+		return &Error{Kind: ErrNonceMismatch, Expected: opts.Nonce, Got: m.Nonce}
+	}
+	if opts.ChainID != 0 && m.ChainID != opts.ChainID {
+		return &Error{Kind: ErrChainIDMismatch, Expected: fmt.Sprint(opts.ChainID), Got: fmt.Sprint(m.ChainID)}
+	}
+
+	now := opts.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if !m.ExpirationTime.IsZero() && !now.Before(m.ExpirationTime) {
+		return &Error{Kind: ErrExpired, Expected: "before " + m.ExpirationTime.Format(time.RFC3339), Got: now.Format(time.RFC3339)}
+	}
+	if !m.NotBefore.IsZero() && now.Before(m.NotBefore) {
+		return &Error{Kind: ErrNotYetValid, Expected: "after " + m.NotBefore.Format(time.RFC3339), Got: now.Format(time.RFC3339)}
+	}
+
+	return nil
+}
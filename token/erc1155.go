@@ -0,0 +1,135 @@
+package token
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tak1827/eth-extended-client/client"
+	"github.com/tak1827/eth-extended-client/contract"
+)
+
+var ErrLengthMismatch = errors.New("length of arrays mismatch")
+
+type ERC1155 struct {
+	client   *client.Client
+	address  common.Address
+	abi      abi.ABI
+	filterer *contract.IERC1155Filterer
+}
+
+// ERC1155Result is the Result with the parsed transfer events, which are empty in Async mode
+type ERC1155Result struct {
+	Result
+	TransferSingles []*contract.IERC1155TransferSingle
+	TransferBatches []*contract.IERC1155TransferBatch
+}
+
+func NewERC1155(c *client.Client, address common.Address) (*ERC1155, error) {
+	parsed, err := abi.JSON(strings.NewReader(contract.IERC1155ABI))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse abi")
+	}
+
+	filterer, err := contract.NewIERC1155Filterer(address, c.Backend())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to bind filterer")
+	}
+
+	return &ERC1155{
+		client:   c,
+		address:  address,
+		abi:      parsed,
+		filterer: filterer,
+	}, nil
+}
+
+func (t *ERC1155) Address() common.Address {
+	return t.address
+}
+
+func (t *ERC1155) BalanceOf(ctx context.Context, account common.Address, id *big.Int) (*big.Int, error) {
+	return callBigInt(ctx, t.client, t.address, &t.abi, "balanceOf", account, id)
+}
+
+// BalanceOfBatch returns the balance of accounts[i] for ids[i]
+func (t *ERC1155) BalanceOfBatch(ctx context.Context, accounts []common.Address, ids []*big.Int) ([]*big.Int, error) {
+	if len(accounts) != len(ids) {
+		return nil, errors.Wrap(ErrLengthMismatch, "accounts and ids")
+	}
+
+	results, err := call(ctx, t.client, t.address, &t.abi, "balanceOfBatch", accounts, ids)
+	if err != nil {
+		return nil, err
+	}
+	return *abi.ConvertType(results[0], new([]*big.Int)).(*[]*big.Int), nil
+}
+
+func (t *ERC1155) IsApprovedForAll(ctx context.Context, account, operator common.Address) (bool, error) {
+	results, err := call(ctx, t.client, t.address, &t.abi, "isApprovedForAll", account, operator)
+	if err != nil {
+		return false, err
+	}
+	return *abi.ConvertType(results[0], new(bool)).(*bool), nil
+}
+
+func (t *ERC1155) URI(ctx context.Context, id *big.Int) (string, error) {
+	results, err := call(ctx, t.client, t.address, &t.abi, "uri", id)
+	if err != nil {
+		return "", err
+	}
+	return *abi.ConvertType(results[0], new(string)).(*string), nil
+}
+
+func (t *ERC1155) SafeTransferFrom(ctx context.Context, priv string, from, to common.Address, id, amount *big.Int, data []byte, mode SendMode) (*ERC1155Result, error) {
+	return t.send(ctx, priv, mode, "safeTransferFrom", from, to, id, amount, data)
+}
+
+func (t *ERC1155) SafeBatchTransferFrom(ctx context.Context, priv string, from, to common.Address, ids, amounts []*big.Int, data []byte, mode SendMode) (*ERC1155Result, error) {
+	if len(ids) != len(amounts) {
+		return nil, errors.Wrap(ErrLengthMismatch, "ids and amounts")
+	}
+	return t.send(ctx, priv, mode, "safeBatchTransferFrom", from, to, ids, amounts, data)
+}
+
+func (t *ERC1155) SetApprovalForAll(ctx context.Context, priv string, operator common.Address, approved bool, mode SendMode) (*ERC1155Result, error) {
+	return t.send(ctx, priv, mode, "setApprovalForAll", operator, approved)
+}
+
+func (t *ERC1155) send(ctx context.Context, priv string, mode SendMode, method string, args ...interface{}) (*ERC1155Result, error) {
+	res, err := send(ctx, t.client, mode, priv, t.address, &t.abi, method, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ERC1155Result{Result: *res}
+	if err = t.parseTransfers(res.Receipt, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (t *ERC1155) parseTransfers(receipt *types.Receipt, result *ERC1155Result) error {
+	for _, l := range eventLogs(receipt, t.address, t.abi.Events["TransferSingle"]) {
+		transfer, err := t.filterer.ParseTransferSingle(l)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse TransferSingle")
+		}
+		result.TransferSingles = append(result.TransferSingles, transfer)
+	}
+
+	for _, l := range eventLogs(receipt, t.address, t.abi.Events["TransferBatch"]) {
+		transfer, err := t.filterer.ParseTransferBatch(l)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse TransferBatch")
+		}
+		result.TransferBatches = append(result.TransferBatches, transfer)
+	}
+
+	return nil
+}
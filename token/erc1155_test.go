@@ -0,0 +1,122 @@
+package token
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tak1827/eth-extended-client/client"
+	"github.com/tak1827/eth-extended-client/contract"
+)
+
+func TestERC1155ParseTransfers(t *testing.T) {
+	var (
+		addr     = common.HexToAddress("0x1")
+		operator = common.HexToAddress(TestAccount)
+		from     = common.Address{}
+		to       = common.HexToAddress(TestAccount2)
+	)
+
+	parsed, err := abi.JSON(strings.NewReader(contract.IERC1155ABI))
+	require.NoError(t, err)
+	filterer, err := contract.NewIERC1155Filterer(addr, nil)
+	require.NoError(t, err)
+	token := &ERC1155{address: addr, abi: parsed, filterer: filterer}
+
+	newLog := func(address common.Address, event string, args ...interface{}) *types.Log {
+		data, err := parsed.Events[event].Inputs.NonIndexed().Pack(args...)
+		require.NoError(t, err)
+		return &types.Log{
+			Address: address,
+			Topics:  []common.Hash{parsed.Events[event].ID, operator.Hash(), from.Hash(), to.Hash()},
+			Data:    data,
+		}
+	}
+
+	receipt := &types.Receipt{Logs: []*types.Log{
+		newLog(addr, "TransferSingle", big.NewInt(1), big.NewInt(10)),
+		newLog(addr, "TransferBatch", []*big.Int{big.NewInt(2), big.NewInt(3)}, []*big.Int{big.NewInt(20), big.NewInt(30)}),
+		// emitted by the other contract
+		newLog(common.HexToAddress("0x2"), "TransferSingle", big.NewInt(4), big.NewInt(40)),
+	}}
+
+	var result ERC1155Result
+	require.NoError(t, token.parseTransfers(receipt, &result))
+
+	require.Len(t, result.TransferSingles, 1)
+	require.Equal(t, operator, result.TransferSingles[0].Operator)
+	require.Equal(t, to, result.TransferSingles[0].To)
+	require.Equal(t, big.NewInt(1), result.TransferSingles[0].Id)
+	require.Equal(t, big.NewInt(10), result.TransferSingles[0].Value)
+
+	require.Len(t, result.TransferBatches, 1)
+	require.Equal(t, []*big.Int{big.NewInt(2), big.NewInt(3)}, result.TransferBatches[0].Ids)
+	require.Equal(t, []*big.Int{big.NewInt(20), big.NewInt(30)}, result.TransferBatches[0].Values)
+}
+
+func TestERC1155Send(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		f       = newFakeNode()
+		c       = newFakeClient(t, f)
+		addr    = common.HexToAddress("0x01")
+		owner   = common.HexToAddress(TestAccount)
+		to, _   = client.GenerateAddr()
+		ids     = []*big.Int{big.NewInt(1), big.NewInt(2)}
+		amounts = []*big.Int{big.NewInt(10), big.NewInt(20)}
+		data    = []byte{0x01}
+	)
+	defer f.Close()
+
+	erc1155, err := NewERC1155(c, addr)
+	require.NoError(t, err)
+
+	lastSent := func(method string, args ...interface{}) *types.Transaction {
+		sent := f.sentTxs()
+		input, err := erc1155.abi.Pack(method, args...)
+		require.NoError(t, err)
+		tx := sent[len(sent)-1]
+		require.Equal(t, addr, *tx.To())
+		require.Equal(t, input, tx.Data())
+		return tx
+	}
+
+	single, err := erc1155.abi.Events["TransferSingle"].Inputs.NonIndexed().Pack(ids[0], amounts[0])
+	require.NoError(t, err)
+	f.setLogs(&types.Log{Address: addr, Topics: []common.Hash{erc1155.abi.Events["TransferSingle"].ID, owner.Hash(), owner.Hash(), to.Hash()}, Data: single})
+
+	// sync
+	res, err := erc1155.SafeTransferFrom(ctx, TestPrivKey, owner, to, ids[0], amounts[0], data, Sync)
+	require.NoError(t, err)
+	require.NotNil(t, res.Receipt)
+	require.Len(t, res.TransferSingles, 1)
+	require.Equal(t, to, res.TransferSingles[0].To)
+	require.Equal(t, amounts[0], res.TransferSingles[0].Value)
+	require.Equal(t, res.Hash, lastSent("safeTransferFrom", owner, to, ids[0], amounts[0], data).Hash().Hex())
+
+	// async
+	res, err = erc1155.SafeBatchTransferFrom(ctx, TestPrivKey, owner, to, ids, amounts, data, Async)
+	require.NoError(t, err)
+	require.Nil(t, res.Receipt)
+	require.Empty(t, res.TransferSingles)
+	require.Equal(t, res.Hash, lastSent("safeBatchTransferFrom", owner, to, ids, amounts, data).Hash().Hex())
+
+	res, err = erc1155.SetApprovalForAll(ctx, TestPrivKey, to, true, Async)
+	require.NoError(t, err)
+	require.Nil(t, res.Receipt)
+	require.Equal(t, res.Hash, lastSent("setApprovalForAll", to, true).Hash().Hex())
+
+	// the mismatched arrays are not sent
+	_, err = erc1155.SafeBatchTransferFrom(ctx, TestPrivKey, owner, to, ids, amounts[:1], data, Async)
+	require.ErrorIs(t, err, ErrLengthMismatch)
+	require.Len(t, f.sentTxs(), 3)
+
+	f.setStatus(types.ReceiptStatusFailed)
+	_, err = erc1155.SetApprovalForAll(ctx, TestPrivKey, to, false, Sync)
+	require.ErrorIs(t, err, ErrTxFailed)
+}
@@ -0,0 +1,187 @@
+package token
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/tak1827/eth-extended-client/client"
+	"github.com/tak1827/eth-extended-client/contract"
+)
+
+var (
+	ErrInvalidAmount = errors.New("invalid amount")
+)
+
+type ERC20 struct {
+	client    *client.Client
+	address   common.Address
+	abi       abi.ABI
+	permitABI abi.ABI
+	filterer  *contract.ERC20Filterer
+
+	sync.Mutex
+	decimals *uint8
+}
+
+// ERC20Result is the Result with the parsed Transfer events, which are empty in Async mode
+type ERC20Result struct {
+	Result
+	Transfers []*contract.ERC20Transfer
+}
+
+func NewERC20(c *client.Client, address common.Address) (*ERC20, error) {
+	parsed, err := abi.JSON(strings.NewReader(contract.ERC20ABI))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse abi")
+	}
+
+	permitABI, err := abi.JSON(strings.NewReader(contract.IERC20PermitABI))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse permit abi")
+	}
+
+	filterer, err := contract.NewERC20Filterer(address, c.Backend())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to bind filterer")
+	}
+
+	return &ERC20{
+		client:    c,
+		address:   address,
+		abi:       parsed,
+		permitABI: permitABI,
+		filterer:  filterer,
+	}, nil
+}
+
+func (t *ERC20) Address() common.Address {
+	return t.address
+}
+
+func (t *ERC20) BalanceOf(ctx context.Context, account common.Address) (*big.Int, error) {
+	return callBigInt(ctx, t.client, t.address, &t.abi, "balanceOf", account)
+}
+
+func (t *ERC20) Allowance(ctx context.Context, owner, spender common.Address) (*big.Int, error) {
+	return callBigInt(ctx, t.client, t.address, &t.abi, "allowance", owner, spender)
+}
+
+func (t *ERC20) TotalSupply(ctx context.Context) (*big.Int, error) {
+	return callBigInt(ctx, t.client, t.address, &t.abi, "totalSupply")
+}
+
+// Decimals is cached after the first call
+func (t *ERC20) Decimals(ctx context.Context) (uint8, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.decimals != nil {
+		return *t.decimals, nil
+	}
+
+	results, err := call(ctx, t.client, t.address, &t.abi, "decimals")
+	if err != nil {
+		return 0, err
+	}
+
+	decimals := *abi.ConvertType(results[0], new(uint8)).(*uint8)
+	t.decimals = &decimals
+	return decimals, nil
+}
+
+// ToAmount converts the human-unit amount, such as "1.5" or 1.5, to the amount in the smallest unit.
+// The amount finer than the decimals of the token is rejected, instead of being truncated.
+func (t *ERC20) ToAmount(ctx context.Context, human interface{}) (*big.Int, error) {
+	decimals, err := t.Decimals(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get decimals")
+	}
+	return toAmount(human, decimals)
+}
+
+func toAmount(human interface{}, decimals uint8) (*big.Int, error) {
+	var (
+		amount decimal.Decimal
+		err    error
+	)
+
+	switch v := human.(type) {
+	case string:
+		if amount, err = decimal.NewFromString(v); err != nil {
+			return nil, errors.Wrapf(ErrInvalidAmount, "failed to parse %q", v)
+		}
+	case float64:
+		amount = decimal.NewFromFloat(v)
+	case int:
+		amount = decimal.NewFromInt(int64(v))
+	case int64:
+		amount = decimal.NewFromInt(v)
+	case uint64:
+		amount = decimal.NewFromBigInt(new(big.Int).SetUint64(v), 0)
+	case decimal.Decimal:
+		amount = v
+	case *decimal.Decimal:
+		if v == nil {
+			return nil, errors.Wrap(ErrInvalidAmount, "nil amount")
+		}
+		amount = *v
+	default:
+		return nil, errors.Wrapf(ErrInvalidAmount, "unsupported type(=%T)", human)
+	}
+
+	if amount.IsNegative() {
+		return nil, errors.Wrapf(ErrInvalidAmount, "negative amount(=%s)", amount)
+	}
+
+	scaled := amount.Shift(int32(decimals))
+	if !scaled.Equal(scaled.Truncate(0)) {
+		return nil, errors.Wrapf(ErrInvalidAmount, "%s has more than %d decimals", amount, decimals)
+	}
+
+	return scaled.BigInt(), nil
+}
+
+// ToHuman converts the amount in the smallest unit to the human-unit amount
+func (t *ERC20) ToHuman(ctx context.Context, amount *big.Int) (decimal.Decimal, error) {
+	decimals, err := t.Decimals(ctx)
+	if err != nil {
+		return decimal.Decimal{}, errors.Wrap(err, "failed to get decimals")
+	}
+	return decimal.NewFromBigInt(amount, -int32(decimals)), nil
+}
+
+func (t *ERC20) Transfer(ctx context.Context, priv string, to common.Address, amount *big.Int, mode SendMode) (*ERC20Result, error) {
+	return t.send(ctx, priv, mode, "transfer", to, amount)
+}
+
+func (t *ERC20) TransferFrom(ctx context.Context, priv string, from, to common.Address, amount *big.Int, mode SendMode) (*ERC20Result, error) {
+	return t.send(ctx, priv, mode, "transferFrom", from, to, amount)
+}
+
+func (t *ERC20) Approve(ctx context.Context, priv string, spender common.Address, amount *big.Int, mode SendMode) (*ERC20Result, error) {
+	return t.send(ctx, priv, mode, "approve", spender, amount)
+}
+
+func (t *ERC20) send(ctx context.Context, priv string, mode SendMode, method string, args ...interface{}) (*ERC20Result, error) {
+	res, err := send(ctx, t.client, mode, priv, t.address, &t.abi, method, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ERC20Result{Result: *res}
+	for _, l := range eventLogs(res.Receipt, t.address, t.abi.Events["Transfer"]) {
+		transfer, err := t.filterer.ParseTransfer(l)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse Transfer")
+		}
+		result.Transfers = append(result.Transfers, transfer)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,142 @@
+package token
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"github.com/tak1827/eth-extended-client/client"
+	"github.com/tak1827/eth-extended-client/contract"
+	"github.com/tak1827/transaction-confirmer/confirm"
+)
+
+const (
+	TestEndpoint = "http://localhost:8545"
+	TestPrivKey  = "d1c71e71b06e248c8dbe94d49ef6d6b0d64f5d71b1e33a0f39e14dadb070304a"
+	TestAccount  = "0xE3b0DE0E4CA5D3CB29A9341534226C4D31C9838f"
+	TestPrivKey2 = "8179ce3d00ac1d1d1d38e4f038de00ccd0e0375517164ac5448e3acc847acb34"
+	TestAccount2 = "0x26fa9f1a6568b42e29b1787c403B3628dFC0C6FE"
+)
+
+func newTestClient(t *testing.T) *client.Client {
+	var (
+		ctx     = context.Background()
+		cfmOpts = []confirm.Opt{
+			confirm.WithWorkers(1),
+			confirm.WithWorkerInterval(64),
+			confirm.WithConfirmationBlock(0),
+		}
+	)
+
+	c, err := client.NewClient(ctx, TestEndpoint, cfmOpts, client.WithTimeout(10), client.WithSyncSendConfirmInterval(64))
+	require.NoError(t, err)
+
+	c.Start()
+	t.Cleanup(c.Stop)
+
+	return &c
+}
+
+func deploy(t *testing.T, c *client.Client, abiJSON, bin string, args ...interface{}) common.Address {
+	var (
+		ctx       = context.Background()
+		parsed, _ = abi.JSON(strings.NewReader(abiJSON))
+		input, _  = parsed.Pack("", args...)
+	)
+
+	hash, err := c.SyncSend(ctx, TestPrivKey, nil, nil, append(common.FromHex(bin), input...), 0)
+	require.NoError(t, err)
+
+	time.Sleep(1 * time.Second)
+
+	receipt, err := c.Receipt(ctx, hash)
+	require.NoError(t, err)
+	return receipt.ContractAddress
+}
+
+func TestERC20(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		c         = newTestClient(t)
+		owner     = common.HexToAddress(TestAccount)
+		spender   = common.HexToAddress(TestAccount2)
+		to, _     = client.GenerateAddr()
+		addr      = deploy(t, c, contract.ERC20ABI, contract.ERC20Bin, "name", "symbol")
+		parsed, _ = abi.JSON(strings.NewReader(contract.ERC20ABI))
+	)
+
+	erc20, err := NewERC20(c, addr)
+	require.NoError(t, err)
+
+	decimals, err := erc20.Decimals(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint8(18), decimals)
+
+	amount, err := erc20.ToAmount(ctx, "1.5")
+	require.NoError(t, err)
+	require.Equal(t, client.ToWei(1.5, 18).String(), amount.String())
+
+	human, err := erc20.ToHuman(ctx, amount)
+	require.NoError(t, err)
+	require.True(t, decimal.NewFromFloat(1.5).Equal(human))
+
+	mint, _ := parsed.Pack("mint", owner, amount)
+	_, err = c.SyncSend(ctx, TestPrivKey, &addr, nil, mint, 0)
+	require.NoError(t, err)
+
+	// sync
+	result, err := erc20.Transfer(ctx, TestPrivKey, to, client.ToWei(1.0, 18), Sync)
+	require.NoError(t, err)
+	require.NotNil(t, result.Receipt)
+	require.Len(t, result.Transfers, 1)
+	require.Equal(t, owner, result.Transfers[0].From)
+	require.Equal(t, to, result.Transfers[0].To)
+	require.Equal(t, client.ToWei(1.0, 18).String(), result.Transfers[0].Value.String())
+
+	balance, err := erc20.BalanceOf(ctx, to)
+	require.NoError(t, err)
+	require.Equal(t, client.ToWei(1.0, 18).String(), balance.String())
+
+	// async
+	result, err = erc20.Approve(ctx, TestPrivKey, spender, amount, Async)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Hash)
+	require.Nil(t, result.Receipt)
+
+	time.Sleep(2 * time.Second)
+
+	allowance, err := erc20.Allowance(ctx, owner, spender)
+	require.NoError(t, err)
+	require.Equal(t, amount.String(), allowance.String())
+}
+
+func TestToAmount(t *testing.T) {
+	for _, c := range []struct {
+		human    interface{}
+		expected string
+	}{
+		{"1.5", "1500000"},
+		{"1.234567", "1234567"},
+		{"1.2345670", "1234567"},
+		{1.5, "1500000"},
+		{5, "5000000"},
+		{int64(5), "5000000"},
+		{uint64(5), "5000000"},
+		{decimal.RequireFromString("0.000001"), "1"},
+	} {
+		amount, err := toAmount(c.human, 6)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, amount.String())
+	}
+
+	for _, human := range []interface{}{"1.2345678", "abc", "", "-1", 0.0000001, int32(5), big.NewInt(5), nil} {
+		_, err := toAmount(human, 6)
+		require.ErrorIs(t, err, ErrInvalidAmount, "%v", human)
+	}
+}
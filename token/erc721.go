@@ -0,0 +1,183 @@
+package token
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tak1827/eth-extended-client/client"
+	"github.com/tak1827/eth-extended-client/contract"
+)
+
+// ERC721 sends every tx through SyncSend, so that the minting campaigns get the nonce management and confirmation
+type ERC721 struct {
+	client   *client.Client
+	address  common.Address
+	abi      abi.ABI
+	filterer *contract.IERC721Filterer
+}
+
+// ERC721Result is the Result with the parsed Transfer events
+type ERC721Result struct {
+	Result
+	Transfers []*contract.IERC721Transfer
+}
+
+func NewERC721(c *client.Client, address common.Address) (*ERC721, error) {
+	parsed, err := abi.JSON(strings.NewReader(contract.IERC721ABI))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse abi")
+	}
+
+	filterer, err := contract.NewIERC721Filterer(address, c.Backend())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to bind filterer")
+	}
+
+	return &ERC721{
+		client:   c,
+		address:  address,
+		abi:      parsed,
+		filterer: filterer,
+	}, nil
+}
+
+func (t *ERC721) Address() common.Address {
+	return t.address
+}
+
+func (t *ERC721) OwnerOf(ctx context.Context, tokenID *big.Int) (common.Address, error) {
+	results, err := call(ctx, t.client, t.address, &t.abi, "ownerOf", tokenID)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return *abi.ConvertType(results[0], new(common.Address)).(*common.Address), nil
+}
+
+func (t *ERC721) BalanceOf(ctx context.Context, owner common.Address) (*big.Int, error) {
+	return callBigInt(ctx, t.client, t.address, &t.abi, "balanceOf", owner)
+}
+
+func (t *ERC721) GetApproved(ctx context.Context, tokenID *big.Int) (common.Address, error) {
+	results, err := call(ctx, t.client, t.address, &t.abi, "getApproved", tokenID)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return *abi.ConvertType(results[0], new(common.Address)).(*common.Address), nil
+}
+
+func (t *ERC721) IsApprovedForAll(ctx context.Context, owner, operator common.Address) (bool, error) {
+	results, err := call(ctx, t.client, t.address, &t.abi, "isApprovedForAll", owner, operator)
+	if err != nil {
+		return false, err
+	}
+	return *abi.ConvertType(results[0], new(bool)).(*bool), nil
+}
+
+func (t *ERC721) SafeMint(ctx context.Context, priv string, tokenID *big.Int, to common.Address, uri string) (*ERC721Result, error) {
+	return t.send(ctx, priv, "safeMint", tokenID, to, uri)
+}
+
+func (t *ERC721) TransferFrom(ctx context.Context, priv string, from, to common.Address, tokenID *big.Int) (*ERC721Result, error) {
+	return t.send(ctx, priv, "transferFrom", from, to, tokenID)
+}
+
+func (t *ERC721) SafeTransferFrom(ctx context.Context, priv string, from, to common.Address, tokenID *big.Int) (*ERC721Result, error) {
+	return t.send(ctx, priv, "safeTransferFrom", from, to, tokenID)
+}
+
+func (t *ERC721) SafeTransferFromWithData(ctx context.Context, priv string, from, to common.Address, tokenID *big.Int, data []byte) (*ERC721Result, error) {
+	return t.send(ctx, priv, "safeTransferFrom0", from, to, tokenID, data)
+}
+
+func (t *ERC721) Approve(ctx context.Context, priv string, to common.Address, tokenID *big.Int) (*ERC721Result, error) {
+	return t.send(ctx, priv, "approve", to, tokenID)
+}
+
+func (t *ERC721) SetApprovalForAll(ctx context.Context, priv string, operator common.Address, approved bool) (*ERC721Result, error) {
+	return t.send(ctx, priv, "setApprovalForAll", operator, approved)
+}
+
+// Transfers returns the Transfer events between the blocks, end is the latest block when nil.
+// The range is split by the log range limit of the client, so that the providers accept it.
+func (t *ERC721) Transfers(ctx context.Context, start uint64, end *uint64) ([]*contract.IERC721Transfer, error) {
+	logs, err := t.client.FilterLogs(ctx, client.LogQuery{
+		ABI:       &t.abi,
+		Events:    []string{"Transfer"},
+		Addresses: []common.Address{t.address},
+		FromBlock: start,
+		ToBlock:   end,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to filter Transfer")
+	}
+
+	transfers := make([]*contract.IERC721Transfer, len(logs))
+	for i := range logs {
+		if transfers[i], err = t.filterer.ParseTransfer(logs[i].Raw); err != nil {
+			return nil, errors.Wrap(err, "failed to parse Transfer")
+		}
+	}
+
+	return transfers, nil
+}
+
+// TokensOf enumerates the tokens owned by the owner, by replaying the Transfer events from the start block
+func (t *ERC721) TokensOf(ctx context.Context, owner common.Address, start uint64) ([]*big.Int, error) {
+	transfers, err := t.Transfers(ctx, start, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ownedTokens(transfers, owner), nil
+}
+
+func ownedTokens(transfers []*contract.IERC721Transfer, owner common.Address) []*big.Int {
+	sort.SliceStable(transfers, func(i, j int) bool {
+		if transfers[i].Raw.BlockNumber != transfers[j].Raw.BlockNumber {
+			return transfers[i].Raw.BlockNumber < transfers[j].Raw.BlockNumber
+		}
+		return transfers[i].Raw.Index < transfers[j].Raw.Index
+	})
+
+	owned := make(map[string]*big.Int)
+	for _, transfer := range transfers {
+		if transfer.Raw.Removed {
+			continue
+		}
+		key := transfer.TokenId.String()
+		if transfer.To == owner {
+			owned[key] = transfer.TokenId
+		} else if transfer.From == owner {
+			delete(owned, key)
+		}
+	}
+
+	tokens := make([]*big.Int, 0, len(owned))
+	for _, id := range owned {
+		tokens = append(tokens, id)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].Cmp(tokens[j]) < 0 })
+	return tokens
+}
+
+func (t *ERC721) send(ctx context.Context, priv string, method string, args ...interface{}) (*ERC721Result, error) {
+	res, err := send(ctx, t.client, Sync, priv, t.address, &t.abi, method, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ERC721Result{Result: *res}
+	for _, l := range eventLogs(res.Receipt, t.address, t.abi.Events["Transfer"]) {
+		transfer, err := t.filterer.ParseTransfer(l)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse Transfer")
+		}
+		result.Transfers = append(result.Transfers, transfer)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,97 @@
+package token
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tak1827/eth-extended-client/client"
+	"github.com/tak1827/eth-extended-client/contract"
+)
+
+func TestOwnedTokens(t *testing.T) {
+	var (
+		owner = common.HexToAddress(TestAccount)
+		other = common.HexToAddress(TestAccount2)
+		zero  = common.Address{}
+	)
+
+	transfer := func(block uint64, index uint, from, to common.Address, id int64) *contract.IERC721Transfer {
+		return &contract.IERC721Transfer{From: from, To: to, TokenId: big.NewInt(id), Raw: types.Log{BlockNumber: block, Index: index}}
+	}
+
+	transfers := []*contract.IERC721Transfer{
+		// out of order on purpose
+		transfer(3, 0, owner, other, 2),
+		transfer(1, 0, zero, owner, 1),
+		transfer(1, 1, zero, owner, 2),
+		transfer(2, 0, zero, other, 3),
+		transfer(4, 0, other, owner, 3),
+	}
+
+	require.Equal(t, []*big.Int{big.NewInt(1), big.NewInt(3)}, ownedTokens(transfers, owner))
+	require.Equal(t, []*big.Int{big.NewInt(2)}, ownedTokens(transfers, other))
+}
+
+func TestERC721Send(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		f        = newFakeNode()
+		c        = newFakeClient(t, f)
+		addr     = common.HexToAddress("0x01")
+		owner    = common.HexToAddress(TestAccount)
+		to, _    = client.GenerateAddr()
+		tokenID  = big.NewInt(7)
+		approved = true
+	)
+	defer f.Close()
+
+	erc721, err := NewERC721(c, addr)
+	require.NoError(t, err)
+
+	transfer := erc721.abi.Events["Transfer"]
+	f.setLogs(
+		&types.Log{Address: addr, Topics: []common.Hash{transfer.ID, owner.Hash(), to.Hash(), common.BigToHash(tokenID)}},
+		// emitted by the other contract
+		&types.Log{Address: common.HexToAddress("0x02"), Topics: []common.Hash{transfer.ID, owner.Hash(), to.Hash(), common.BigToHash(tokenID)}},
+	)
+
+	for _, s := range []struct {
+		send   func() (*ERC721Result, error)
+		method string
+		args   []interface{}
+	}{
+		{func() (*ERC721Result, error) { return erc721.SafeMint(ctx, TestPrivKey, tokenID, to, "uri") }, "safeMint", []interface{}{tokenID, to, "uri"}},
+		{func() (*ERC721Result, error) { return erc721.TransferFrom(ctx, TestPrivKey, owner, to, tokenID) }, "transferFrom", []interface{}{owner, to, tokenID}},
+		{func() (*ERC721Result, error) { return erc721.SafeTransferFrom(ctx, TestPrivKey, owner, to, tokenID) }, "safeTransferFrom", []interface{}{owner, to, tokenID}},
+		{func() (*ERC721Result, error) {
+			return erc721.SafeTransferFromWithData(ctx, TestPrivKey, owner, to, tokenID, []byte{0x01})
+		}, "safeTransferFrom0", []interface{}{owner, to, tokenID, []byte{0x01}}},
+		{func() (*ERC721Result, error) { return erc721.Approve(ctx, TestPrivKey, to, tokenID) }, "approve", []interface{}{to, tokenID}},
+		{func() (*ERC721Result, error) { return erc721.SetApprovalForAll(ctx, TestPrivKey, to, approved) }, "setApprovalForAll", []interface{}{to, approved}},
+	} {
+		res, err := s.send()
+		require.NoError(t, err, s.method)
+
+		// always sent in Sync mode
+		require.NotNil(t, res.Receipt, s.method)
+		require.Len(t, res.Transfers, 1, s.method)
+		require.Equal(t, owner, res.Transfers[0].From)
+		require.Equal(t, to, res.Transfers[0].To)
+		require.Equal(t, tokenID, res.Transfers[0].TokenId)
+
+		sent := f.sentTxs()
+		input, err := erc721.abi.Pack(s.method, s.args...)
+		require.NoError(t, err)
+		require.Equal(t, res.Hash, sent[len(sent)-1].Hash().Hex(), s.method)
+		require.Equal(t, addr, *sent[len(sent)-1].To(), s.method)
+		require.Equal(t, input, sent[len(sent)-1].Data(), s.method)
+	}
+
+	f.setStatus(types.ReceiptStatusFailed)
+	_, err = erc721.TransferFrom(ctx, TestPrivKey, owner, to, tokenID)
+	require.ErrorIs(t, err, ErrTxFailed)
+}
@@ -0,0 +1,141 @@
+package token
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/tak1827/eth-extended-client/client"
+)
+
+var (
+	ErrPermitExpired    = errors.New("permit is expired")
+	ErrNotPermitSpender = errors.New("relayer is not the spender of the permit")
+
+	PermitTypeHash = crypto.Keccak256Hash([]byte("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)"))
+)
+
+// Permit is the EIP-2612 approval signed by the owner, which is submitted by anyone
+type Permit struct {
+	Owner    common.Address
+	Spender  common.Address
+	Value    *big.Int
+	Nonce    *big.Int
+	Deadline *big.Int
+	V        uint8
+	R        [32]byte
+	S        [32]byte
+}
+
+// PermitTransferResult is the results of the permit and the following transferFrom
+type PermitTransferResult struct {
+	Permit   Result
+	Transfer ERC20Result
+}
+
+func (t *ERC20) Nonces(ctx context.Context, owner common.Address) (*big.Int, error) {
+	return callBigInt(ctx, t.client, t.address, &t.permitABI, "nonces", owner)
+}
+
+func (t *ERC20) DomainSeparator(ctx context.Context) (common.Hash, error) {
+	results, err := call(ctx, t.client, t.address, &t.permitABI, "DOMAIN_SEPARATOR")
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return *abi.ConvertType(results[0], new([32]byte)).(*[32]byte), nil
+}
+
+// SignPermit signs the permit of the signer to the spender, reading the nonce and the domain separator from the token
+func (t *ERC20) SignPermit(ctx context.Context, signer client.Signer, spender common.Address, value, deadline *big.Int) (*Permit, error) {
+	if deadline.Cmp(big.NewInt(time.Now().Unix())) < 0 {
+		return nil, errors.Wrapf(ErrPermitExpired, "deadline(=%v)", deadline)
+	}
+
+	nonce, err := t.Nonces(ctx, signer.Address())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get nonce")
+	}
+
+	domainSeparator, err := t.DomainSeparator(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get domain separator")
+	}
+
+	permit := &Permit{
+		Owner:    signer.Address(),
+		Spender:  spender,
+		Value:    value,
+		Nonce:    nonce,
+		Deadline: deadline,
+	}
+
+	sig, err := signer.SignHash(permitHash(domainSeparator, permit))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign permit")
+	}
+
+	copy(permit.R[:], sig[:32])
+	copy(permit.S[:], sig[32:64])
+	permit.V = sig[crypto.RecoveryIDOffset]
+	if permit.V < 27 {
+		permit.V += 27
+	}
+
+	return permit, nil
+}
+
+// SubmitPermit sends the permit by the relayer, so the owner doesn't need ETH to approve
+func (t *ERC20) SubmitPermit(ctx context.Context, relayerPriv string, permit *Permit, mode SendMode) (*ERC20Result, error) {
+	return t.sendPermit(ctx, relayerPriv, mode, "permit", permit.Owner, permit.Spender, permit.Value, permit.Deadline, permit.V, permit.R, permit.S)
+}
+
+// PermitTransferFrom sends the permit and the transferFrom of the amount from the owner to the to by the relayer,
+// which should be the spender of the permit
+func (t *ERC20) PermitTransferFrom(ctx context.Context, relayerPriv string, permit *Permit, to common.Address, amount *big.Int) (*PermitTransferResult, error) {
+	relayer, err := client.NewKeySigner(relayerPriv)
+	if err != nil {
+		return nil, err
+	}
+	if relayer.Address() != permit.Spender {
+		return nil, errors.Wrapf(ErrNotPermitSpender, "relayer(=%s), spender(=%s)", relayer.Address(), permit.Spender)
+	}
+
+	permitRes, err := t.SubmitPermit(ctx, relayerPriv, permit, Sync)
+	if err != nil {
+		return nil, err
+	}
+
+	transferRes, err := t.TransferFrom(ctx, relayerPriv, permit.Owner, to, amount, Sync)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PermitTransferResult{Permit: permitRes.Result, Transfer: *transferRes}, nil
+}
+
+func (t *ERC20) sendPermit(ctx context.Context, priv string, mode SendMode, method string, args ...interface{}) (*ERC20Result, error) {
+	res, err := send(ctx, t.client, mode, priv, t.address, &t.permitABI, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &ERC20Result{Result: *res}, nil
+}
+
+// permitHash returns the EIP-712 digest of the permit under the domain separator of the token
+func permitHash(domainSeparator common.Hash, permit *Permit) common.Hash {
+	structHash := crypto.Keccak256(
+		PermitTypeHash.Bytes(),
+		common.LeftPadBytes(permit.Owner.Bytes(), 32),
+		common.LeftPadBytes(permit.Spender.Bytes(), 32),
+		math.U256Bytes(new(big.Int).Set(permit.Value)),
+		math.U256Bytes(new(big.Int).Set(permit.Nonce)),
+		math.U256Bytes(new(big.Int).Set(permit.Deadline)),
+	)
+
+	return crypto.Keccak256Hash([]byte("\x19\x01"), domainSeparator.Bytes(), structHash)
+}
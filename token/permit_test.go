@@ -0,0 +1,58 @@
+package token
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/require"
+	"github.com/tak1827/eth-extended-client/client"
+)
+
+func TestPermitHash(t *testing.T) {
+	var (
+		token  = common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+		domain = client.NewTypedDataDomain("Permit Token", "1", big.NewInt(1337), token)
+		permit = &Permit{
+			Owner:    common.HexToAddress(TestAccount),
+			Spender:  common.HexToAddress(TestAccount2),
+			Value:    big.NewInt(1000),
+			Nonce:    big.NewInt(2),
+			Deadline: big.NewInt(1700000000),
+		}
+	)
+
+	typedData := client.NewTypedData(domain, "Permit", apitypes.Types{
+		"Permit": {
+			{Name: "owner", Type: "address"},
+			{Name: "spender", Type: "address"},
+			{Name: "value", Type: "uint256"},
+			{Name: "nonce", Type: "uint256"},
+			{Name: "deadline", Type: "uint256"},
+		},
+	}, apitypes.TypedDataMessage{
+		"owner":    permit.Owner.Hex(),
+		"spender":  permit.Spender.Hex(),
+		"value":    (*math.HexOrDecimal256)(permit.Value),
+		"nonce":    (*math.HexOrDecimal256)(permit.Nonce),
+		"deadline": (*math.HexOrDecimal256)(permit.Deadline),
+	})
+	expected, err := client.TypedDataHash(typedData)
+	require.NoError(t, err)
+
+	// the domain separator is read from the token on chain
+	domainSeparator, err := typedData.HashStruct(client.EIP712DomainType, domain.Map())
+	require.NoError(t, err)
+
+	require.Equal(t, expected, permitHash(common.BytesToHash(domainSeparator), permit))
+
+	signer, err := client.NewKeySigner(TestPrivKey)
+	require.NoError(t, err)
+	sig, err := client.SignTypedData(signer, typedData)
+	require.NoError(t, err)
+	addr, err := client.RecoverTypedDataSigner(typedData, sig)
+	require.NoError(t, err)
+	require.Equal(t, permit.Owner, addr)
+}
@@ -0,0 +1,102 @@
+package token
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tak1827/eth-extended-client/client"
+)
+
+var (
+	ErrTxFailed = errors.New("tx failed")
+)
+
+type SendMode int
+
+const (
+	// Sync waits until the tx is confirmed, and parses the events from the receipt
+	Sync SendMode = iota
+	// Async returns right after the tx is sent
+	Async
+)
+
+// Result is the result of the send. The Receipt is nil in Async mode.
+// In Sync mode, the reverted tx fails the send with ErrTxFailed.
+type Result struct {
+	Hash    string
+	Receipt *types.Receipt
+}
+
+func send(ctx context.Context, c *client.Client, mode SendMode, priv string, to common.Address, parsed *abi.ABI, method string, args ...interface{}) (*Result, error) {
+	input, err := parsed.Pack(method, args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to pack %s", method)
+	}
+
+	if mode == Async {
+		hash, err := c.AsyncSend(ctx, priv, &to, nil, input, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to send %s", method)
+		}
+		return &Result{Hash: hash}, nil
+	}
+
+	hash, err := c.SyncSend(ctx, priv, &to, nil, input, 0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to send %s", method)
+	}
+
+	receipt, err := c.Receipt(ctx, hash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get receipt of %s", hash)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, errors.Wrapf(ErrTxFailed, "%s, tx=%s", method, hash)
+	}
+
+	return &Result{Hash: hash, Receipt: receipt}, nil
+}
+
+func call(ctx context.Context, c *client.Client, to common.Address, parsed *abi.ABI, method string, args ...interface{}) ([]interface{}, error) {
+	input, err := parsed.Pack(method, args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to pack %s", method)
+	}
+
+	output, err := c.QueryContract(ctx, to, input)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to call %s", method)
+	}
+
+	results, err := parsed.Unpack(method, output)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unpack %s", method)
+	}
+	return results, nil
+}
+
+func callBigInt(ctx context.Context, c *client.Client, to common.Address, parsed *abi.ABI, method string, args ...interface{}) (*big.Int, error) {
+	results, err := call(ctx, c, to, parsed, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	return *abi.ConvertType(results[0], new(*big.Int)).(**big.Int), nil
+}
+
+// eventLogs returns the logs of the event emitted by the contract
+func eventLogs(receipt *types.Receipt, contract common.Address, event abi.Event) []types.Log {
+	var logs []types.Log
+	if receipt == nil {
+		return logs
+	}
+	for _, l := range receipt.Logs {
+		if l.Address == contract && len(l.Topics) > 0 && l.Topics[0] == event.ID {
+			logs = append(logs, *l)
+		}
+	}
+	return logs
+}
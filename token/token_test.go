@@ -0,0 +1,218 @@
+package token
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tak1827/eth-extended-client/client"
+	"github.com/tak1827/transaction-confirmer/confirm"
+)
+
+type fakeHandler func(params []json.RawMessage) (interface{}, error)
+
+// fakeNode is a minimal json-rpc server, which mines every sent tx with the receipt of the status and the logs
+type fakeNode struct {
+	*httptest.Server
+	sync.Mutex
+	handlers map[string]fakeHandler
+	sent     []*types.Transaction
+	status   uint64
+	logs     []*types.Log
+}
+
+func newFakeNode() *fakeNode {
+	f := &fakeNode{
+		handlers: make(map[string]fakeHandler),
+		status:   types.ReceiptStatusSuccessful,
+	}
+	f.handle("eth_chainId", func([]json.RawMessage) (interface{}, error) {
+		return hexutil.Uint64(1010), nil
+	})
+	f.handle("eth_blockNumber", func([]json.RawMessage) (interface{}, error) {
+		return hexutil.Uint64(10), nil
+	})
+	f.handle("eth_getBlockByNumber", func([]json.RawMessage) (interface{}, error) {
+		return &types.Header{Number: big.NewInt(10), Difficulty: big.NewInt(0), BaseFee: big.NewInt(1)}, nil
+	})
+	f.handle("eth_syncing", func([]json.RawMessage) (interface{}, error) {
+		return false, nil
+	})
+	f.handle("eth_maxPriorityFeePerGas", func([]json.RawMessage) (interface{}, error) {
+		return (*hexutil.Big)(big.NewInt(1)), nil
+	})
+	f.handle("eth_getTransactionCount", func([]json.RawMessage) (interface{}, error) {
+		return hexutil.Uint64(0), nil
+	})
+	f.handle("eth_estimateGas", func([]json.RawMessage) (interface{}, error) {
+		return hexutil.Uint64(50000), nil
+	})
+	f.handle(client.MethodSendRawTransaction, func(params []json.RawMessage) (interface{}, error) {
+		var raw hexutil.Bytes
+		_ = json.Unmarshal(params[0], &raw)
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return nil, err
+		}
+		f.Lock()
+		f.sent = append(f.sent, tx)
+		f.Unlock()
+		return tx.Hash(), nil
+	})
+	f.handle(client.MethodGetTransactionReceipt, func(params []json.RawMessage) (interface{}, error) {
+		var hash common.Hash
+		_ = json.Unmarshal(params[0], &hash)
+		f.Lock()
+		defer f.Unlock()
+		logs := make([]*types.Log, len(f.logs))
+		for i := range f.logs {
+			l := *f.logs[i]
+			l.TxHash = hash
+			logs[i] = &l
+		}
+		return &types.Receipt{Status: f.status, TxHash: hash, BlockNumber: big.NewInt(8), Logs: logs}, nil
+	})
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+func (f *fakeNode) handle(method string, h fakeHandler) {
+	f.Lock()
+	f.handlers[method] = h
+	f.Unlock()
+}
+
+func (f *fakeNode) setStatus(status uint64) {
+	f.Lock()
+	f.status = status
+	f.Unlock()
+}
+
+func (f *fakeNode) setLogs(logs ...*types.Log) {
+	f.Lock()
+	f.logs = logs
+	f.Unlock()
+}
+
+// sentTxs returns the txs sent so far
+func (f *fakeNode) sentTxs() []*types.Transaction {
+	f.Lock()
+	defer f.Unlock()
+	return append([]*types.Transaction{}, f.sent...)
+}
+
+func (f *fakeNode) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	type rpcError struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	type response struct {
+		Version string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Result  interface{}     `json:"result,omitempty"`
+		Error   *rpcError       `json:"error,omitempty"`
+	}
+
+	body, _ := io.ReadAll(r.Body)
+
+	var (
+		reqs  []request
+		batch = len(bytes.TrimSpace(body)) > 0 && bytes.TrimSpace(body)[0] == '['
+	)
+	if batch {
+		_ = json.Unmarshal(body, &reqs)
+	} else {
+		var req request
+		_ = json.Unmarshal(body, &req)
+		reqs = []request{req}
+	}
+
+	resps := make([]response, len(reqs))
+	for i, req := range reqs {
+		f.Lock()
+		h, ok := f.handlers[req.Method]
+		f.Unlock()
+
+		resps[i] = response{Version: "2.0", ID: req.ID}
+		if !ok {
+			resps[i].Error = &rpcError{Code: -32601, Message: "the method " + req.Method + " does not exist/is not available"}
+			continue
+		}
+
+		result, err := h(req.Params)
+		if err != nil {
+			resps[i].Error = &rpcError{Code: -32000, Message: err.Error()}
+			continue
+		}
+		if result == nil {
+			result = json.RawMessage("null")
+		}
+		resps[i].Result = result
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if batch {
+		_ = json.NewEncoder(w).Encode(resps)
+	} else {
+		_ = json.NewEncoder(w).Encode(resps[0])
+	}
+}
+
+// newFakeClient starts the client on the fake node, which confirms the txs right away
+func newFakeClient(t *testing.T, f *fakeNode) *client.Client {
+	cfmOpts := []confirm.Opt{confirm.WithWorkerInterval(10), confirm.WithConfirmationBlock(0)}
+	c, err := client.NewClient(context.Background(), f.URL, cfmOpts, client.WithSyncSendConfirmInterval(10))
+	require.NoError(t, err)
+
+	c.Start()
+	t.Cleanup(c.Stop)
+
+	return &c
+}
+
+func TestSendFailed(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		f     = newFakeNode()
+		c     = newFakeClient(t, f)
+		token = common.HexToAddress("0x01")
+		to, _ = client.GenerateAddr()
+	)
+	defer f.Close()
+
+	erc20, err := NewERC20(c, token)
+	require.NoError(t, err)
+
+	res, err := erc20.Transfer(ctx, TestPrivKey, to, big.NewInt(1), Sync)
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, res.Receipt.Status)
+
+	// the reverted tx is not reported as success
+	f.setStatus(types.ReceiptStatusFailed)
+	_, err = erc20.Transfer(ctx, TestPrivKey, to, big.NewInt(1), Sync)
+	require.ErrorIs(t, err, ErrTxFailed)
+
+	// the transferFrom is not sent after the reverted permit
+	permit := &Permit{Owner: common.HexToAddress(TestAccount), Spender: common.HexToAddress(TestAccount2), Value: big.NewInt(1), Deadline: big.NewInt(1)}
+	_, err = erc20.PermitTransferFrom(ctx, TestPrivKey2, permit, to, big.NewInt(1))
+	require.ErrorIs(t, err, ErrTxFailed)
+
+	sent := f.sentTxs()
+	require.Len(t, sent, 3)
+	require.Equal(t, erc20.permitABI.Methods["permit"].ID, sent[2].Data()[:4])
+}